@@ -0,0 +1,251 @@
+package browser
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/go-rod/rod/lib/utils"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// AXNode 无障碍树节点
+type AXNode struct {
+	States        map[string]string      `json:"states,omitempty"`
+	Role          string                 `json:"role"`
+	Name          string                 `json:"name,omitempty"`
+	Value         string                 `json:"value,omitempty"`
+	Description   string                 `json:"description,omitempty"`
+	Children      []*AXNode              `json:"children,omitempty"`
+	BackendNodeID proto.DOMBackendNodeID `json:"backendNodeId,omitempty"`
+}
+
+// AccessibilityOptions 无障碍树快照选项
+type AccessibilityOptions struct {
+	// Selector 根元素选择器，为空时为整个页面
+	Selector string
+	// MaxDepth 最大深度，0 为不限制
+	MaxDepth int
+	// All 保留被忽略及无语义的节点，默认只保留有意义的节点
+	All bool
+}
+
+// axStates 快照中保留的状态属性
+var axStates = map[proto.AccessibilityAXPropertyName]bool{
+	proto.AccessibilityAXPropertyNameChecked:         true,
+	proto.AccessibilityAXPropertyNameDisabled:        true,
+	proto.AccessibilityAXPropertyNameExpanded:        true,
+	proto.AccessibilityAXPropertyNameSelected:        true,
+	proto.AccessibilityAXPropertyNamePressed:         true,
+	proto.AccessibilityAXPropertyNameLevel:           true,
+	proto.AccessibilityAXPropertyNameRequired:        true,
+	proto.AccessibilityAXPropertyNameReadonly:        true,
+	proto.AccessibilityAXPropertyNameFocused:         true,
+	proto.AccessibilityAXPropertyNameModal:           true,
+	proto.AccessibilityAXPropertyNameMultiselectable: true,
+	proto.AccessibilityAXPropertyNameInvalid:         true,
+	proto.AccessibilityAXPropertyNameBusy:            true,
+}
+
+// AccessibilitySnapshot 获取页面的无障碍树，可通过 String 输出缩进的文本
+func (page *Page) AccessibilitySnapshot(opts ...func(o *AccessibilityOptions)) (*AXNode, error) {
+	o := zutil.Optional(AccessibilityOptions{}, opts...)
+
+	p := page.Timeout().page
+	res, err := proto.AccessibilityGetFullAXTree{FrameID: p.FrameID}.Call(p)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode, len(res.Nodes))
+	var root *proto.AccessibilityAXNode
+	for _, v := range res.Nodes {
+		nodes[v.NodeID] = v
+		if root == nil && v.ParentID == "" {
+			root = v
+		}
+	}
+	if root == nil {
+		return nil, errors.New("accessibility tree is empty")
+	}
+
+	if o.Selector != "" {
+		ele, err := page.Timeout().Element(o.Selector)
+		if err != nil {
+			return nil, err
+		}
+		node, err := ele.element.Describe(0, false)
+		if err != nil {
+			return nil, err
+		}
+
+		root = nil
+		for _, v := range res.Nodes {
+			if v.BackendDOMNodeID == node.BackendNodeID {
+				root = v
+				break
+			}
+		}
+		if root == nil {
+			return nil, errors.New("element is not in the accessibility tree")
+		}
+	}
+
+	list := buildAXNode(root, nodes, o, 1)
+	if len(list) == 1 {
+		return list[0], nil
+	}
+
+	return &AXNode{Role: axValue(root.Role), Children: list, BackendNodeID: root.BackendDOMNodeID}, nil
+}
+
+// buildAXNode 构建节点，无意义的节点会被移除并将子节点提升到上一级
+func buildAXNode(n *proto.AccessibilityAXNode, nodes map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode, o AccessibilityOptions, depth int) []*AXNode {
+	node := &AXNode{
+		Role:          axValue(n.Role),
+		Name:          axValue(n.Name),
+		Value:         axValue(n.Value),
+		Description:   axValue(n.Description),
+		BackendNodeID: n.BackendDOMNodeID,
+	}
+	if !o.All && node.Role == "InlineTextBox" {
+		return nil
+	}
+
+	for _, v := range n.Properties {
+		if !axStates[v.Name] {
+			continue
+		}
+		value := axValue(v.Value)
+		if value == "" || value == "false" {
+			continue
+		}
+		if node.States == nil {
+			node.States = make(map[string]string)
+		}
+		node.States[string(v.Name)] = value
+	}
+
+	var children []*AXNode
+	if o.MaxDepth <= 0 || depth < o.MaxDepth {
+		for _, id := range n.ChildIDs {
+			if child, ok := nodes[id]; ok {
+				children = append(children, buildAXNode(child, nodes, o, depth+1)...)
+			}
+		}
+	}
+
+	if !o.All {
+		if len(children) == 1 && children[0].Role == "StaticText" && children[0].Name == node.Name {
+			children = nil
+		}
+		if n.Ignored || (node.Name == "" && node.States == nil && (node.Role == "generic" || node.Role == "none" ||
+			node.Role == "presentation" || node.Role == "LineBreak")) {
+			return children
+		}
+	}
+
+	node.Children = children
+	return []*AXNode{node}
+}
+
+func axValue(v *proto.AccessibilityAXValue) string {
+	if v == nil || v.Value.Nil() {
+		return ""
+	}
+	return strings.TrimSpace(v.Value.Str())
+}
+
+// String 输出缩进的 YAML 风格文本
+func (n *AXNode) String() string {
+	var b strings.Builder
+	n.write(&b, 0)
+	return b.String()
+}
+
+func (n *AXNode) write(b *strings.Builder, indent int) {
+	b.WriteString(strings.Repeat("  ", indent))
+	b.WriteString("- ")
+	if n.Role == "StaticText" {
+		b.WriteString("text: ")
+		b.WriteString(strconv.Quote(n.Name))
+		b.WriteString("\n")
+		return
+	}
+
+	b.WriteString(n.Role)
+	if n.Name != "" {
+		b.WriteString(" ")
+		b.WriteString(strconv.Quote(n.Name))
+	}
+
+	keys := make([]string, 0, len(n.States))
+	for k := range n.States {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := n.States[k]; v == "true" {
+			b.WriteString(" [" + k + "]")
+		} else {
+			b.WriteString(" [" + k + "=" + v + "]")
+		}
+	}
+
+	if n.Value != "" {
+		b.WriteString(": ")
+		b.WriteString(strconv.Quote(n.Value))
+	}
+	if n.Description != "" && n.Value == "" && len(n.Children) == 0 {
+		b.WriteString(": ")
+		b.WriteString(strconv.Quote(n.Description))
+	}
+	b.WriteString("\n")
+
+	for _, child := range n.Children {
+		child.write(b, indent+1)
+	}
+}
+
+// ByRole 按无障碍角色及名称获取元素，name 为空时只匹配角色，会等待元素出现
+func (page *Page) ByRole(role, name string) (*Element, error) {
+	p := page.Timeout().page
+
+	var ele *rod.Element
+	err := utils.Retry(p.GetContext(), rod.DefaultSleeper(), func() (bool, error) {
+		doc, err := p.Sleeper(rod.NotFoundSleeper).ElementByJS(rod.Eval(`() => document.documentElement`))
+		if err != nil {
+			return false, nil
+		}
+
+		res, err := proto.AccessibilityQueryAXTree{
+			ObjectID:       doc.Object.ObjectID,
+			Role:           role,
+			AccessibleName: name,
+		}.Call(p)
+		if err != nil {
+			return true, err
+		}
+
+		for _, v := range res.Nodes {
+			if v.Ignored || v.BackendDOMNodeID == 0 {
+				continue
+			}
+			ele, err = page.page.ElementFromNode(&proto.DOMNode{BackendNodeID: v.BackendDOMNodeID})
+			return true, err
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Element{
+		element: ele,
+		page:    page,
+	}, nil
+}
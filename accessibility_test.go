@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo"
+	"github.com/ysmood/gson"
+)
+
+func Test_buildAXNode(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	value := func(v interface{}) *proto.AccessibilityAXValue {
+		return &proto.AccessibilityAXValue{Value: gson.New(v)}
+	}
+	list := []*proto.AccessibilityAXNode{
+		{NodeID: "1", Role: value("RootWebArea"), Name: value("Demo"), ChildIDs: []proto.AccessibilityAXNodeID{"2", "5"}},
+		{NodeID: "2", ParentID: "1", Role: value("generic"), ChildIDs: []proto.AccessibilityAXNodeID{"3"}},
+		{NodeID: "3", ParentID: "2", Role: value("heading"), Name: value("Title"), BackendDOMNodeID: 10, ChildIDs: []proto.AccessibilityAXNodeID{"4"},
+			Properties: []*proto.AccessibilityAXProperty{{Name: proto.AccessibilityAXPropertyNameLevel, Value: value(1)}}},
+		{NodeID: "4", ParentID: "3", Role: value("StaticText"), Name: value("Title")},
+		{NodeID: "5", ParentID: "1", Role: value("checkbox"), Name: value("Remember"), Ignored: false,
+			Properties: []*proto.AccessibilityAXProperty{
+				{Name: proto.AccessibilityAXPropertyNameChecked, Value: value("true")},
+				{Name: proto.AccessibilityAXPropertyNameFocusable, Value: value(true)},
+				{Name: proto.AccessibilityAXPropertyNameDisabled, Value: value(false)},
+			}},
+	}
+	nodes := make(map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode)
+	for _, v := range list {
+		nodes[v.NodeID] = v
+	}
+
+	root := buildAXNode(list[0], nodes, AccessibilityOptions{}, 1)
+	tt.Equal(1, len(root))
+	tt.Equal(2, len(root[0].Children))
+	tt.Equal(proto.DOMBackendNodeID(10), root[0].Children[0].BackendNodeID)
+	tt.Equal("- RootWebArea \"Demo\"\n  - heading \"Title\" [level=1]\n  - checkbox \"Remember\" [checked]\n", root[0].String())
+
+	root = buildAXNode(list[0], nodes, AccessibilityOptions{MaxDepth: 1}, 1)
+	tt.Equal(0, len(root[0].Children))
+
+	root = buildAXNode(list[0], nodes, AccessibilityOptions{All: true}, 1)
+	tt.Equal("generic", root[0].Children[0].Role)
+}
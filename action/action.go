@@ -1,7 +1,6 @@
 package action
 
 import (
-	"context"
 	"errors"
 	"time"
 
@@ -26,6 +25,7 @@ func (o TimeoutType) Do(p *browser.Page, as Actions, panicErr bool) (s any, err
 }
 
 type ClickType struct {
+	locator  *browser.Locator
 	selector string
 }
 
@@ -38,7 +38,18 @@ func Click(selector string) ClickType {
 	}
 }
 
+// ClickLocator 点击定位器匹配的元素，定位器在当前动作的页面中查找
+func ClickLocator(l *browser.Locator) ClickType {
+	return ClickType{
+		locator: l,
+	}
+}
+
 func (o ClickType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
+	if o.locator != nil {
+		return nil, o.locator.On(p).Click()
+	}
+
 	element, has := ExtractElement(parentResults...)
 	if has {
 		if o.selector != "" {
@@ -78,57 +89,48 @@ func RaceElement(successSelectors, failedSelectors []string, maxRetry int, timeo
 }
 
 func (o RaceElementType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
-	maxRetry := o.maxRetry
-	var run func() (ele *browser.Element, err error)
-	run = func() (ele *browser.Element, err error) {
-		page := p
-		fns := make(map[string]browser.RaceElementFunc, len(o.SuccessSelectors)+len(o.FailedSelectors))
-		if o.timeout > 0 {
-			page = p.Timeout(o.timeout + (time.Duration(maxRetry-o.maxRetry) * time.Second))
-		}
-		all := append(o.SuccessSelectors, o.FailedSelectors...)
-		for i := range all {
-			v := all[i]
-			if v == "" {
-				continue
-			}
-			if _, ok := fns[v]; ok {
-				return nil, errors.New("selector must be unique: " + v)
-			}
+	retried := 0
+	race := p.Race().Retry(o.maxRetry, true, func(p *browser.Page) error {
+		retried++
+		return p.Reload()
+	})
+	if o.timeout > 0 {
+		race = race.Timeout(o.timeout)
+	}
 
-			fns[v] = browser.RaceElementFunc{
-				Element: func(p *browser.Page) (bool, *browser.Element) {
-					return page.HasElement(v)
-				},
-				Handle: func(element *browser.Element) (retry bool, err error) {
-					if zarray.Contains(o.SuccessSelectors, v) {
-						return false, nil
-					}
-					o.maxRetry--
-					if o.maxRetry > 0 {
-						return true, nil
-					}
-					return false, errors.New("failed to find element: " + v)
-				},
-			}
+	var ele *browser.Element
+	exist := make(map[string]struct{}, len(o.SuccessSelectors)+len(o.FailedSelectors))
+	all := append(append([]string{}, o.SuccessSelectors...), o.FailedSelectors...)
+	for i := range all {
+		v := all[i]
+		if v == "" {
+			continue
+		}
+		if _, ok := exist[v]; ok {
+			return nil, errors.New("selector must be unique: " + v)
 		}
+		exist[v] = struct{}{}
 
-		_, ele, err = page.RaceElement(fns)
-		if err == context.DeadlineExceeded {
-			if o.maxRetry > 0 {
-				o.maxRetry--
-				err = p.Reload()
-				if err != nil {
-					return nil, err
-				}
-				return run()
+		success := zarray.Contains(o.SuccessSelectors, v)
+		race.Element(v, v, func(element *browser.Element) error {
+			ele = element
+			if success {
+				return nil
 			}
-			return nil, err
-		}
-		return
+			err := errors.New("failed to find element: " + v)
+			if retried+1 < o.maxRetry {
+				return browser.RaceRetry(err)
+			}
+			return err
+		})
 	}
 
-	return run()
+	_, err = race.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return ele, nil
 }
 
 func (o RaceElementType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
@@ -172,6 +174,27 @@ func (o IfElementType) Next(p *browser.Page, as Actions, value ActionResult) ([]
 	return as.Run(p, value)
 }
 
+type LocateType struct {
+	locator *browser.Locator
+}
+
+var _ ActionType = LocateType{}
+
+// Locate 通过定位器查找元素，定位器在当前动作的页面中查找，结果为元素
+func Locate(l *browser.Locator) LocateType {
+	return LocateType{
+		locator: l,
+	}
+}
+
+func (o LocateType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
+	return o.locator.On(p).Element()
+}
+
+func (o LocateType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
+	return as.Run(p, value)
+}
+
 type ElementsType struct {
 	selector string
 	filter   []string
@@ -5,7 +5,6 @@ import (
 	"reflect"
 	"time"
 
-	"github.com/go-rod/rod/lib/proto"
 	"github.com/sohaha/zlsgo/zarray"
 	"github.com/sohaha/zlsgo/zerror"
 	"github.com/sohaha/zlsgo/zfile"
@@ -176,6 +175,7 @@ func (o InputEnterType) Next(p *browser.Page, as Actions, value ActionResult) ([
 }
 
 type ScreenshoType struct {
+	opts     func(o *browser.ScreenshotOptions)
 	selector string
 	file     string
 }
@@ -190,6 +190,20 @@ func Screenshot(file string, selector ...string) ScreenshoType {
 	return s
 }
 
+// ScreenshotWith 按选项截图
+func ScreenshotWith(file string, opts func(o *browser.ScreenshotOptions), selector ...string) ScreenshoType {
+	s := Screenshot(file, selector...)
+	s.opts = opts
+	return s
+}
+
+func (o ScreenshoType) options() []func(o *browser.ScreenshotOptions) {
+	if o.opts == nil {
+		return nil
+	}
+	return []func(o *browser.ScreenshotOptions){o.opts}
+}
+
 func (o ScreenshoType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
 	file := o.file
 	if file == "" && len(parentResults) > 0 {
@@ -209,12 +223,11 @@ func (o ScreenshoType) Do(p *browser.Page, parentResults ...ActionResult) (s any
 			}
 		}
 
-		bin, err := element.ROD().Timeout(time.Second*3).Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
-		if err != nil {
+		if err = element.Timeout(time.Second*3).Screenshot(file, o.options()...); err != nil {
 			return nil, errors.New("screenshot failed")
 		}
 
-		return file, zfile.WriteFile(file, bin)
+		return file, nil
 	}
 
 	page, has := ExtractPage(parentResults...)
@@ -222,13 +235,21 @@ func (o ScreenshoType) Do(p *browser.Page, parentResults ...ActionResult) (s any
 		p = page
 	}
 	if o.selector != "" {
-		p.MustElement(o.selector).ROD().MustScreenshot(file)
-		return
+		element, err = p.Element(o.selector)
+		if err == nil {
+			err = element.Screenshot(file, o.options()...)
+		}
 	} else {
-		p.ROD().MustScreenshotFullPage(file)
+		// 默认截取整个页面，选项中的 FullPage 可以覆盖
+		err = p.Screenshot(file, append([]func(o *browser.ScreenshotOptions){func(o *browser.ScreenshotOptions) {
+			o.FullPage = true
+		}}, o.options()...)...)
 	}
-	s = zfile.SafePath(file)
-	return
+	if err != nil {
+		return nil, err
+	}
+
+	return zfile.SafePath(file), nil
 }
 
 func (o ScreenshoType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
@@ -236,25 +257,25 @@ func (o ScreenshoType) Next(p *browser.Page, as Actions, value ActionResult) ([]
 }
 
 type ScreenshoFullType struct {
+	opts []func(o *browser.ScreenshotOptions)
 	file string
 }
 
 var _ ActionType = ScreenshoFullType{}
 
 // ScreenshotFullPage 截图整个页面
-func ScreenshotFullPage(file string) ScreenshoFullType {
-	return ScreenshoFullType{file: file}
+func ScreenshotFullPage(file string, opts ...func(o *browser.ScreenshotOptions)) ScreenshoFullType {
+	return ScreenshoFullType{file: file, opts: opts}
 }
 
 func (o ScreenshoFullType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
 	_ = p.WaitDOMStable(0)
 	file := zfile.RealPath(o.file)
-	bin, err := p.ROD().Screenshot(true, nil)
-	if err != nil {
+	if err = p.ScreenshotFullPage(file, o.opts...); err != nil {
 		return nil, errors.New("screenshot failed")
 	}
 
-	return zfile.SafePath(file), zfile.WriteFile(file, bin)
+	return zfile.SafePath(file), nil
 }
 
 func (o ScreenshoFullType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
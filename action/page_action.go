@@ -125,3 +125,36 @@ func (o ActivatePageType) Do(p *browser.Page, parentResults ...ActionResult) (s
 func (o ActivatePageType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
 	return as.Run(p, value)
 }
+
+type PaginateType struct {
+	actions Actions
+	opts    []func(o *browser.PaginateOptions)
+}
+
+var _ ActionType = PaginateType{}
+
+// Paginate 翻页并在每一页执行 actions，结果为每页的执行结果
+func Paginate(actions Actions, opts ...func(o *browser.PaginateOptions)) PaginateType {
+	return PaginateType{
+		actions: actions,
+		opts:    opts,
+	}
+}
+
+func (o PaginateType) Do(p *browser.Page, parentResults ...ActionResult) (s any, err error) {
+	pages := make([]ActionResult, 0)
+	_, err = p.Paginate(func(p *browser.Page, n int) error {
+		res := ActionResult{Name: "page", Value: n}
+		res.Child, err = o.actions.Run(p)
+		if err != nil {
+			return err
+		}
+		pages = append(pages, res)
+		return nil
+	}, o.opts...)
+	return pages, err
+}
+
+func (o PaginateType) Next(p *browser.Page, as Actions, value ActionResult) ([]ActionResult, error) {
+	return as.Run(p, value)
+}
@@ -3,7 +3,9 @@ package action
 import (
 	"time"
 
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/sohaha/zlsgo/zjson"
+	"github.com/zlsgo/browser"
 )
 
 func parseAction(actionArray []*zjson.Res) (actions Actions) {
@@ -33,13 +35,32 @@ func parseAction(actionArray []*zjson.Res) (actions Actions) {
 		case "Elements":
 			action.Action = Elements(selector, vaidator.Slice().String()...)
 		case "Screenshot":
-			action.Action = Screenshot("")
+			action.Action = ScreenshotWith(value, parseScreenshotOptions(v.Get("screenshot")), selector)
 		case "ClickNewPage":
 			action.Action = ClickNewPage(selector)
 		case "ActivatePage":
 			action.Action = ActivatePage()
 		case "ClosePage":
 			action.Action = ClosePage()
+		case "Paginate":
+			paginate := v.Get("paginate")
+			action.Action = Paginate(nextActions, func(o *browser.PaginateOptions) {
+				o.Next = selector
+				o.URL = value
+				o.Links = paginate.Get("links").String()
+				o.LoadMore = paginate.Get("loadMore").String()
+				if content := paginate.Get("content").String(); content != "" {
+					o.Content = content
+				}
+				if start := paginate.Get("start").Int(); start > 0 {
+					o.Start = start
+				}
+				o.MaxPages = paginate.Get("max").Int()
+				if timeout > 0 {
+					o.Wait = time.Second * time.Duration(timeout)
+				}
+			})
+			action.Next = nil
 		default:
 			if actionType, ok := actionTypeMap[actionType]; ok {
 				action = actionType(v)
@@ -53,6 +74,42 @@ func parseAction(actionArray []*zjson.Res) (actions Actions) {
 	return
 }
 
+// parseScreenshotOptions 解析截图选项，例如
+// {"format": "jpeg", "quality": 80, "fullPage": true, "mask": [".phone"], "hide": [".ad"], "clip": {"x": 0, "y": 0, "width": 100, "height": 100}}
+func parseScreenshotOptions(v *zjson.Res) func(o *browser.ScreenshotOptions) {
+	return func(o *browser.ScreenshotOptions) {
+		if !v.Exists() {
+			return
+		}
+		if format := v.Get("format").String(); format != "" {
+			o.Format = proto.PageCaptureScreenshotFormat(format)
+		}
+		o.Quality = v.Get("quality").Int()
+		if scale := v.Get("scale").Float(); scale > 0 {
+			o.Scale = scale
+		}
+		if fullPage := v.Get("fullPage"); fullPage.Exists() {
+			o.FullPage = fullPage.Bool()
+		}
+		o.OmitBackground = v.Get("omitBackground").Bool()
+		o.KeepCaret = v.Get("keepCaret").Bool()
+		o.KeepAnimations = v.Get("keepAnimations").Bool()
+		o.Mask = v.Get("mask").Slice().String()
+		o.Hide = v.Get("hide").Slice().String()
+		if color := v.Get("maskColor").String(); color != "" {
+			o.MaskColor = color
+		}
+		if clip := v.Get("clip"); clip.Exists() {
+			o.Clip = &proto.PageViewport{
+				X:      clip.Get("x").Float(),
+				Y:      clip.Get("y").Float(),
+				Width:  clip.Get("width").Float(),
+				Height: clip.Get("height").Float(),
+			}
+		}
+	}
+}
+
 var actionTypeMap = map[string]func(v *zjson.Res) Action{}
 
 func CustomActionType(name string, action func(v *zjson.Res) Action) {
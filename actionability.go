@@ -0,0 +1,192 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/utils"
+)
+
+// ActionabilityCheck 元素可操作性检查项
+type ActionabilityCheck string
+
+const (
+	// CheckAttached 元素在文档中
+	CheckAttached ActionabilityCheck = "attached"
+	// CheckVisible 元素可见
+	CheckVisible ActionabilityCheck = "visible"
+	// CheckStable 元素位置在连续的动画帧中保持不变
+	CheckStable ActionabilityCheck = "stable"
+	// CheckEnabled 元素未被禁用
+	CheckEnabled ActionabilityCheck = "enabled"
+	// CheckReceivesEvents 元素中心点未被其他元素遮挡
+	CheckReceivesEvents ActionabilityCheck = "receivesEvents"
+	// CheckEditable 元素可编辑
+	CheckEditable ActionabilityCheck = "editable"
+)
+
+var (
+	clickChecks = []ActionabilityCheck{CheckAttached, CheckVisible, CheckStable, CheckEnabled, CheckReceivesEvents}
+	inputChecks = []ActionabilityCheck{CheckAttached, CheckVisible, CheckEnabled, CheckEditable}
+)
+
+// ActionabilityError 可操作性检查失败
+type ActionabilityError struct {
+	Err    error
+	Check  ActionabilityCheck
+	Detail string
+}
+
+func (e *ActionabilityError) Error() string {
+	var msg string
+	switch e.Check {
+	case CheckAttached:
+		msg = "element is not attached to the document"
+	case CheckVisible:
+		msg = "element is not visible"
+	case CheckStable:
+		msg = "element is not stable, it is still moving"
+	case CheckEnabled:
+		msg = "element is disabled"
+	case CheckReceivesEvents:
+		msg = "element does not receive pointer events"
+	case CheckEditable:
+		msg = "element is not editable"
+	default:
+		msg = "element is not actionable: " + string(e.Check)
+	}
+
+	if e.Detail != "" {
+		msg += ", " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ActionabilityError) Unwrap() error {
+	return e.Err
+}
+
+var jsActionability = `async (checks) => {
+	const el = this;
+	const has = c => checks.includes(c);
+	const describe = n => {
+		let s = n.tagName.toLowerCase();
+		if (n.id) s += "#" + n.id;
+		if (typeof n.className === "string" && n.className.trim()) s += "." + n.className.trim().split(/\s+/).join(".");
+		return "<" + s + ">";
+	};
+	const frame = () => new Promise(r => requestAnimationFrame(() => r()));
+
+	if (!el.isConnected) return { check: "attached" };
+
+	if (has("visible")) {
+		const style = getComputedStyle(el);
+		const rect = el.getBoundingClientRect();
+		if (style.visibility === "hidden" || style.visibility === "collapse" || rect.width === 0 || rect.height === 0) {
+			return { check: "visible" };
+		}
+	}
+
+	if (has("enabled")) {
+		const disabled = ["BUTTON", "INPUT", "SELECT", "TEXTAREA", "OPTION", "OPTGROUP", "FIELDSET"].includes(el.tagName) &&
+			el.matches(":disabled");
+		if (disabled || el.closest("[aria-disabled=true]")) return { check: "enabled" };
+	}
+
+	if (has("editable")) {
+		const input = ["INPUT", "TEXTAREA", "SELECT"].includes(el.tagName);
+		if ((input && (el.readOnly || el.hasAttribute("readonly"))) || (!input && !el.isContentEditable) ||
+			el.getAttribute("aria-readonly") === "true") {
+			return { check: "editable" };
+		}
+	}
+
+	if (has("stable")) {
+		let last = el.getBoundingClientRect();
+		for (let i = 0; i < 2; i++) {
+			await frame();
+			const rect = el.getBoundingClientRect();
+			if (rect.x !== last.x || rect.y !== last.y || rect.width !== last.width || rect.height !== last.height) {
+				return { check: "stable" };
+			}
+			last = rect;
+		}
+	}
+
+	if (has("receivesEvents")) {
+		el.scrollIntoView({ block: "center", inline: "center", behavior: "instant" });
+		const rect = el.getBoundingClientRect();
+		const x = rect.left + rect.width / 2, y = rect.top + rect.height / 2;
+		let root = el.ownerDocument, hit = root.elementFromPoint(x, y);
+		while (hit && hit.shadowRoot) {
+			const inner = hit.shadowRoot.elementFromPoint(x, y);
+			if (!inner || inner === hit) break;
+			hit = inner;
+		}
+		let n = hit;
+		while (n && n !== el) n = n.parentElement || (n.getRootNode() instanceof ShadowRoot ? n.getRootNode().host : null);
+		if (!n) {
+			if (getComputedStyle(el).pointerEvents === "none") return { check: "receivesEvents", detail: "pointer-events is none" };
+			return { check: "receivesEvents", detail: hit ? describe(hit) + " intercepts pointer events" : "" };
+		}
+	}
+
+	return { check: "" };
+}`
+
+// Force 返回跳过可操作性检查的元素副本
+func (e *Element) Force() *Element {
+	return &Element{
+		element: e.element,
+		page:    e.page,
+		force:   true,
+		located: e.located,
+	}
+}
+
+// WaitActionable 等待元素通过可操作性检查，直到页面超时，未指定检查项时按点击检查
+func (e *Element) WaitActionable(checks ...ActionabilityCheck) error {
+	if e.force || e.page.Options.Force {
+		return nil
+	}
+	if len(checks) == 0 {
+		checks = clickChecks
+	}
+
+	list := make([]string, 0, len(checks))
+	for _, v := range checks {
+		list = append(list, string(v))
+	}
+
+	el := e.Timeout().element
+	failed := &ActionabilityError{}
+	err := utils.Retry(el.GetContext(), rod.DefaultSleeper(), func() (bool, error) {
+		res, err := el.Eval(jsActionability, list)
+		if err != nil {
+			return true, err
+		}
+
+		failed.Check = ActionabilityCheck(res.Value.Get("check").Str())
+		failed.Detail = res.Value.Get("detail").Str()
+		if res.Value.Get("detail").Nil() {
+			failed.Detail = ""
+		}
+		if failed.Check == CheckAttached && e.located {
+			return true, nil
+		}
+		return failed.Check == "", nil
+	})
+	if err == nil && failed.Check == CheckAttached {
+		return failed
+	}
+	if err == nil {
+		return nil
+	}
+
+	if failed.Check == "" {
+		return err
+	}
+	failed.Err = err
+	return failed
+}
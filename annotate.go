@@ -0,0 +1,286 @@
+package browser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// AnnotateOptions 标注截图选项
+type AnnotateOptions struct {
+	// Screenshot 截图选项，截取整个页面或指定区域时会标注可视区域外的元素
+	Screenshot func(o *ScreenshotOptions)
+	// Selector 额外需要标注的元素 CSS 选择器
+	Selector string
+	// Color 标注颜色，默认 #FF0050
+	Color string
+}
+
+// Mark 截图中被标注的元素
+type Mark struct {
+	// Element 对应的元素
+	Element *Element `json:"-"`
+	// Tag 标签名
+	Tag string `json:"tag"`
+	// Role 角色，例如 link、button、textbox
+	Role string `json:"role"`
+	// Text 元素的文本、值或 aria-label 等
+	Text string `json:"text"`
+	// Selector 建议使用的选择器
+	Selector string `json:"selector"`
+	// Box 元素在截图中的位置，单位为图片像素
+	Box proto.DOMRect `json:"box"`
+	// Number 标注的序号，从 1 开始
+	Number int `json:"number"`
+}
+
+// Annotation 标注截图结果
+type Annotation struct {
+	// Marks 序号对应的元素
+	Marks map[int]*Mark `json:"marks"`
+	// Image 截图数据
+	Image []byte `json:"-"`
+}
+
+var jsAnnotateElements = `(selector, all) => {
+	const interactive = 'a[href], area[href], button, input:not([type="hidden"]), select, textarea, summary, ' +
+		'[role="button"], [role="link"], [role="checkbox"], [role="radio"], [role="switch"], [role="tab"], ' +
+		'[role="menuitem"], [role="option"], [role="combobox"], [role="textbox"], [onclick], ' +
+		'[contenteditable=""], [contenteditable="true"], [tabindex]:not([tabindex="-1"])';
+	const result = [];
+	for (const el of document.querySelectorAll(selector ? interactive + ", " + selector : interactive)) {
+		if (el.disabled) continue;
+		const r = el.getBoundingClientRect();
+		if (r.width < 1 || r.height < 1) continue;
+		const style = getComputedStyle(el);
+		if (style.visibility === "hidden" || style.display === "none" || Number(style.opacity) === 0) continue;
+
+		const x = r.left + r.width / 2, y = r.top + r.height / 2;
+		const inViewport = x >= 0 && y >= 0 && x < innerWidth && y < innerHeight;
+		if (!all && !inViewport) continue;
+		if (inViewport) {
+			const hit = document.elementFromPoint(x, y);
+			if (hit && !el.contains(hit) && !hit.contains(el)) continue;
+		}
+		result.push(el);
+	}
+	return result;
+}`
+
+var jsAnnotateDraw = `(o, ...elements) => {
+	const normalize = (s) => (s || "").replace(/\s+/g, " ").trim();
+	const unique = (s) => {
+		try {
+			return document.querySelectorAll(s).length === 1;
+		} catch (e) {
+			return false;
+		}
+	};
+	const roles = { a: "link", area: "link", button: "button", select: "combobox", textarea: "textbox", summary: "button" };
+	const role = (el) => {
+		if (el.getAttribute("role")) return el.getAttribute("role");
+		if (el.localName === "input") {
+			const type = (el.getAttribute("type") || "text").toLowerCase();
+			if (["checkbox", "radio"].includes(type)) return type;
+			if (["button", "submit", "reset", "image"].includes(type)) return "button";
+			return "textbox";
+		}
+		return roles[el.localName] || (el.isContentEditable ? "textbox" : "generic");
+	};
+	const text = (el) => {
+		const value = el.localName === "input" && el.type === "password" ? "" : el.value;
+		return normalize(el.getAttribute("aria-label") || el.innerText || value || el.getAttribute("alt") ||
+			el.getAttribute("title") || el.getAttribute("placeholder")).slice(0, 100);
+	};
+	const suggest = (el) => {
+		const testid = el.getAttribute("data-testid");
+		if (testid && unique('[data-testid="' + CSS.escape(testid) + '"]')) return "testid=" + testid;
+		if (el.id && unique("#" + CSS.escape(el.id))) return "#" + CSS.escape(el.id);
+		for (const name of ["name", "aria-label", "placeholder", "title", "href"]) {
+			const value = el.getAttribute(name);
+			const s = el.localName + "[" + name + '="' + CSS.escape(value || "") + '"]';
+			if (value && unique(s)) return s;
+		}
+
+		const path = [];
+		for (let e = el; e && e.nodeType === 1 && e !== document.documentElement; e = e.parentElement) {
+			if (e.id && unique("#" + CSS.escape(e.id))) {
+				path.unshift("#" + CSS.escape(e.id));
+				break;
+			}
+			let s = e.localName, n = 1, same = false;
+			for (let p = e.previousElementSibling; p; p = p.previousElementSibling) {
+				if (p.localName === e.localName) n++;
+			}
+			for (let p = e.nextElementSibling; p && !same; p = p.nextElementSibling) same = p.localName === e.localName;
+			if (n > 1 || same) s += ":nth-of-type(" + n + ")";
+			path.unshift(s);
+		}
+		return path.join(" > ");
+	};
+
+	const root = document.createElement("div");
+	root.style.cssText = "position: absolute; left: 0; top: 0; width: 0; height: 0; overflow: visible; z-index: 2147483647; pointer-events: none;";
+	const x = o.doc ? scrollX : 0, y = o.doc ? scrollY : 0;
+	const items = elements.map((el, i) => {
+		const r = el.getBoundingClientRect();
+		const box = document.createElement("div");
+		box.style.cssText = "position: absolute; box-sizing: border-box; left: " + (r.left + scrollX) + "px; top: " +
+			(r.top + scrollY) + "px; width: " + r.width + "px; height: " + r.height + "px; border: 2px solid " + o.color + ";";
+		const label = document.createElement("span");
+		label.textContent = String(i + 1);
+		label.style.cssText = "position: absolute; left: -2px; top: " + (r.top + scrollY < 16 ? 0 : -16) + "px; padding: 0 3px; " +
+			"font: bold 12px/16px monospace; color: #FFF; background: " + o.color + ";";
+		box.appendChild(label);
+		root.appendChild(box);
+
+		return {
+			tag: el.localName, role: role(el), text: text(el), selector: suggest(el),
+			box: { x: r.left + x, y: r.top + y, width: r.width, height: r.height },
+		};
+	});
+	document.documentElement.appendChild(root);
+	window.__zlsAnnotateRemove = () => {
+		root.remove();
+		delete window.__zlsAnnotateRemove;
+	};
+	return { dpr: devicePixelRatio, items };
+}`
+
+var jsAnnotateRemove = `() => window.__zlsAnnotateRemove && window.__zlsAnnotateRemove()`
+
+// AnnotatedScreenshot 标注页面中的链接、按钮、输入框等可交互元素并截图，
+// 每个元素绘制带序号的边框，之后可以通过 ClickByMark、ElementByMark 操作对应的元素
+func (page *Page) AnnotatedScreenshot(opts ...func(o *AnnotateOptions)) (*Annotation, error) {
+	if page.state == nil {
+		return nil, errPageState
+	}
+
+	o := zutil.Optional(AnnotateOptions{Color: "#FF0050"}, opts...)
+	var (
+		so       ScreenshotOptions
+		shotOpts []func(o *ScreenshotOptions)
+	)
+	if o.Screenshot != nil {
+		o.Screenshot(&so)
+		shotOpts = append(shotOpts, o.Screenshot)
+	}
+
+	p := page.Timeout().page
+	es, err := p.ElementsByJS(rod.Eval(jsAnnotateElements, o.Selector, so.FullPage || so.Clip != nil))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, 0, len(es)+1)
+	args = append(args, map[string]interface{}{"color": o.Color, "doc": page.owner == nil})
+	for i := range es {
+		args = append(args, es[i].Object)
+	}
+	res, err := p.Eval(jsAnnotateDraw, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = p.Eval(jsAnnotateRemove)
+	}()
+
+	var drawn struct {
+		Items []Mark  `json:"items"`
+		DPR   float64 `json:"dpr"`
+	}
+	if err = res.Value.Unmarshal(&drawn); err != nil {
+		return nil, err
+	}
+
+	data, region, err := page.screenshot(shotOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if page.owner != nil {
+		region = &proto.PageViewport{Scale: region.Scale}
+	}
+
+	a := &Annotation{Image: data, Marks: make(map[int]*Mark, len(drawn.Items))}
+	for i := range drawn.Items {
+		m := drawn.Items[i]
+		m.Number = i + 1
+		m.Element = &Element{element: es[i].Context(page.page.GetContext()), page: page}
+		m.Box = markBox(m.Box, region, drawn.DPR)
+		a.Marks[m.Number] = &m
+	}
+
+	page.state.mu.Lock()
+	page.state.marks = a.Marks
+	page.state.mu.Unlock()
+	return a, nil
+}
+
+// markBox 将元素区域转换为截图中的像素区域
+func markBox(box proto.DOMRect, region *proto.PageViewport, dpr float64) proto.DOMRect {
+	scale := region.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if dpr > 0 {
+		scale *= dpr
+	}
+
+	return proto.DOMRect{
+		X:      (box.X - region.X) * scale,
+		Y:      (box.Y - region.Y) * scale,
+		Width:  box.Width * scale,
+		Height: box.Height * scale,
+	}
+}
+
+// String 按序号列出标注的元素，例如 [1] button "登录" #login
+func (a *Annotation) String() string {
+	numbers := make([]int, 0, len(a.Marks))
+	for n := range a.Marks {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var b strings.Builder
+	for _, n := range numbers {
+		m := a.Marks[n]
+		b.WriteString(fmt.Sprintf("[%d] %s", n, m.Role))
+		if m.Text != "" {
+			b.WriteString(fmt.Sprintf(" %q", m.Text))
+		}
+		if m.Selector != "" {
+			b.WriteString(" " + m.Selector)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ElementByMark 获取最近一次 AnnotatedScreenshot 中序号为 n 的元素
+func (page *Page) ElementByMark(n int) (*Element, error) {
+	if page.state == nil {
+		return nil, errPageState
+	}
+
+	page.state.mu.Lock()
+	m, ok := page.state.marks[n]
+	page.state.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("mark %d not found", n)
+	}
+	return m.Element, nil
+}
+
+// ClickByMark 点击最近一次 AnnotatedScreenshot 中序号为 n 的元素
+func (page *Page) ClickByMark(n int, button ...proto.InputMouseButton) error {
+	ele, err := page.ElementByMark(n)
+	if err != nil {
+		return err
+	}
+	return ele.Click(button...)
+}
@@ -0,0 +1,59 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo"
+)
+
+func TestAnnotation(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	box := markBox(proto.DOMRect{X: 20, Y: 150, Width: 30, Height: 10}, &proto.PageViewport{Y: 100, Scale: 1}, 2)
+	tt.Equal(proto.DOMRect{X: 40, Y: 100, Width: 60, Height: 20}, box)
+
+	a := &Annotation{Marks: map[int]*Mark{
+		2: {Number: 2, Role: "textbox", Selector: `input[name="q"]`},
+		1: {Number: 1, Role: "link", Text: "首页", Selector: "#home"},
+	}}
+	tt.Equal("[1] link \"首页\" #home\n[2] textbox input[name=\"q\"]\n", a.String())
+}
+
+func TestAnnotatedScreenshot(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><a id="home" href="#">home</a><button id="save" onclick="document.title='clicked'">save</button></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		a, err := p.AnnotatedScreenshot()
+		if err != nil {
+			return err
+		}
+		tt.Equal(2, len(a.Marks))
+		tt.Equal("#save", a.Marks[2].Selector)
+
+		// 标注元素不应继承截图调用的超时
+		time.Sleep(1500 * time.Millisecond)
+		if err = p.ClickByMark(2); err != nil {
+			return err
+		}
+		title, err := p.EvalJS(`() => document.title`)
+		if err != nil {
+			return err
+		}
+		tt.Equal("clicked", title.Str())
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = time.Second
+	})
+	tt.NoError(err)
+}
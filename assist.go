@@ -1,36 +1,242 @@
 package browser
 
 import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/sohaha/zlsgo/ztype"
 )
 
-// ScreenshotFullPage 截图全屏
-func (p *Page) ScreenshotFullPage(file string) error {
-	b, err := p.ROD().Screenshot(true, nil)
+// SaveMHTML 保存页面为 MHTML 归档
+func (p *Page) SaveMHTML(file string) error {
+	res, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(p.Timeout().page)
 	if err != nil {
 		return err
 	}
 
-	return zfile.WriteFile(zfile.RealPath(file), b)
+	return zfile.WriteFile(zfile.RealPath(file), zstring.String2Bytes(res.Data))
 }
 
-// Screenshot 截图
-func (p *Page) Screenshot(file string) error {
-	b, err := p.ROD().Screenshot(false, nil)
+var (
+	snapshotTokenRegexp  = regexp.MustCompile(`__zls_resource_(\d+)_(\d+)__`)
+	snapshotFrameRegexp  = regexp.MustCompile(` __zls_frame_(\d+)_(\d+)__`)
+	snapshotCSSRegexp    = regexp.MustCompile(`(@import\s+)?url\(\s*(['"]?)([^'")]+)(['"]?)\s*\)`)
+	snapshotImportRegexp = regexp.MustCompile(`@import\s+(['"])([^'"]+)(['"])`)
+	snapshotAttrReplacer = strings.NewReplacer("&", "&amp;", `"`, "&quot;")
+)
+
+// SaveSnapshot 保存页面快照到目录，包含 Shadow DOM、iframe 以及页面资源，
+// 跨域 iframe 通过其框架页面序列化，资源会下载到 resources 目录并改写为本地链接，入口文件为 index.html
+func (p *Page) SaveSnapshot(dir string) error {
+	dir = zfile.RealPath(dir)
+	html, err := p.snapshot(dir, make(map[string]string))
 	if err != nil {
 		return err
 	}
 
-	return zfile.WriteFile(zfile.RealPath(file), b)
+	return zfile.WriteFile(filepath.Join(dir, "index.html"), zstring.String2Bytes(html))
 }
 
-// Screenshot 截图元素
-func (ele *Element) Screenshot(file string) error {
-	b, err := ele.ROD().Screenshot(proto.PageCaptureScreenshotFormatPng, 0)
+// snapshot 序列化页面并保存资源，无法直接访问的 iframe 序列化后以 srcdoc 内联，失败时保留远程地址
+func (p *Page) snapshot(dir string, saved map[string]string) (string, error) {
+	page := p.Timeout().page
+	obj, err := page.Evaluate(rod.Eval(jsSnapshot, closedShadowRoots(page, nil)...).ByObject())
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer func() {
+		_ = proto.RuntimeReleaseObject{ObjectID: obj.ObjectID}.Call(page)
+	}()
+
+	res, err := page.Evaluate(rod.Eval(jsSnapshotResult).This(obj))
+	if err != nil {
+		return "", err
+	}
+
+	resources := res.Value.Get("resources").Arr()
+	locals := make([]string, len(resources))
+	for i := range resources {
+		u := resources[i].Get("url").String()
+		locals[i] = p.saveSnapshotResource(dir, u, resources[i].Get("kind").String() == "css", saved)
+	}
+
+	srcs := res.Value.Get("frames").Arr()
+	frames := make([]string, len(srcs))
+	if len(srcs) > 0 {
+		owners, err := page.ElementsByJS(rod.Eval(jsSnapshotOwners).This(obj))
+		for i := range srcs {
+			frames[i] = ` src="` + snapshotAttrReplacer.Replace(srcs[i].String()) + `"`
+			if err != nil || i >= len(owners) {
+				continue
+			}
+			if frame, ferr := p.snapshotFrame(owners[i], dir, saved); ferr == nil {
+				frames[i] = ` srcdoc="` + snapshotAttrReplacer.Replace(frame) + `"`
+			}
+		}
+	}
+
+	return fillSnapshot(res.Value.Get("html").String(), locals, frames), nil
+}
+
+// fillSnapshot 替换快照中的资源及 iframe 占位符，占位符位于嵌套的 srcdoc 中时按嵌套层数转义
+func fillSnapshot(html string, locals, frames []string) string {
+	html = snapshotTokenRegexp.ReplaceAllStringFunc(html, func(s string) string {
+		m := snapshotTokenRegexp.FindStringSubmatch(s)
+		i := ztype.ToInt(m[1])
+		if i < 0 || i >= len(locals) {
+			return s
+		}
+		return snapshotEscape(locals[i], ztype.ToInt(m[2]))
+	})
+
+	return snapshotFrameRegexp.ReplaceAllStringFunc(html, func(s string) string {
+		m := snapshotFrameRegexp.FindStringSubmatch(s)
+		i := ztype.ToInt(m[1])
+		if i < 0 || i >= len(frames) {
+			return ""
+		}
+		return snapshotEscape(frames[i], ztype.ToInt(m[2]))
+	})
+}
+
+// snapshotEscape 按 srcdoc 的嵌套层数转义
+func snapshotEscape(s string, depth int) string {
+	for i := 0; i < depth; i++ {
+		s = snapshotAttrReplacer.Replace(s)
+	}
+	return s
+}
+
+// snapshotFrame 通过 iframe 对应的框架页面序列化，支持跨进程的 iframe
+func (p *Page) snapshotFrame(owner *rod.Element, dir string, saved map[string]string) (string, error) {
+	node, err := owner.Describe(1, false)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := p.Frames()
+	if err != nil {
+		return "", err
+	}
+	f := root.Find(func(f *Frame) bool {
+		return f.Parent != nil && f.ID == node.FrameID
+	})
+	if f == nil {
+		return "", errFrameNotFound
+	}
+
+	fp, err := f.Page()
+	if err != nil {
+		return "", err
+	}
+	return fp.snapshot(dir, saved)
+}
+
+// saveSnapshotResource 下载资源并返回相对 index.html 的路径，失败时保留远程地址，
+// 样式表中的 url() 及 @import 会递归下载并改写
+func (p *Page) saveSnapshotResource(dir, u string, isCSS bool, saved map[string]string) string {
+	if local, ok := saved[u]; ok {
+		return local
+	}
+
+	name := zstring.Md5(u) + snapshotExt(u, isCSS)
+	local := "resources/" + name
+	saved[u] = local
+
+	b, err := p.fetchResource(u)
+	if err != nil {
+		saved[u] = u
+		return u
+	}
+
+	if isCSS {
+		base, _ := url.Parse(u)
+		css := rewriteSnapshotCSS(zstring.Bytes2String(b), base, func(ref string, isCSS bool) string {
+			l := p.saveSnapshotResource(dir, ref, isCSS, saved)
+			return strings.TrimPrefix(l, "resources/")
+		})
+		b = zstring.String2Bytes(css)
+	}
+
+	if err = zfile.WriteFile(filepath.Join(dir, "resources", name), b); err != nil {
+		saved[u] = u
+		return u
+	}
+
+	return local
+}
+
+// rewriteSnapshotCSS 改写样式表中的 url() 及 @import，save 返回资源的本地路径
+func rewriteSnapshotCSS(css string, base *url.URL, save func(ref string, isCSS bool) string) string {
+	resolve := func(ref string) (string, bool) {
+		ref = strings.TrimSpace(ref)
+		if base == nil || strings.HasPrefix(ref, "data:") {
+			return "", false
+		}
+		r, err := base.Parse(ref)
+		if err != nil || (r.Scheme != "http" && r.Scheme != "https") {
+			return "", false
+		}
+		return r.String(), true
+	}
+
+	css = snapshotImportRegexp.ReplaceAllStringFunc(css, func(s string) string {
+		m := snapshotImportRegexp.FindStringSubmatch(s)
+		u, ok := resolve(m[2])
+		if !ok {
+			return s
+		}
+		return "@import " + m[1] + save(u, true) + m[3]
+	})
+
+	return snapshotCSSRegexp.ReplaceAllStringFunc(css, func(s string) string {
+		m := snapshotCSSRegexp.FindStringSubmatch(s)
+		u, ok := resolve(m[3])
+		if !ok {
+			return s
+		}
+		return m[1] + "url(" + m[2] + save(u, m[1] != "") + m[4] + ")"
+	})
+}
+
+// fetchResource 获取页面资源，优先读取浏览器缓存
+func (p *Page) fetchResource(u string) ([]byte, error) {
+	if b, err := p.page.GetResource(u); err == nil && len(b) > 0 {
+		return b, nil
+	}
+
+	resp, err := p.Client().Get(u)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, errors.New("status code not 200")
+	}
+
+	return resp.Bytes(), nil
+}
+
+func snapshotExt(u string, isCSS bool) string {
+	if isCSS {
+		return ".css"
+	}
+
+	if r, err := url.Parse(u); err == nil {
+		ext := path.Ext(r.Path)
+		if len(ext) > 1 && len(ext) <= 6 {
+			return strings.ToLower(ext)
+		}
 	}
 
-	return zfile.WriteFile(zfile.RealPath(file), b)
+	return ""
 }
@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestRewriteSnapshotCSS(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	base, _ := url.Parse("https://example.com/css/main.css")
+	saved := make(map[string]bool)
+	css := rewriteSnapshotCSS(`@import "reset.css";
+@import url('/theme.css') screen;
+.logo { background: url(../img/logo.png) }
+.icon { background: url("data:image/png;base64,AAAA") }`, base, func(ref string, isCSS bool) string {
+		saved[ref] = isCSS
+		return "local-" + ref[len("https://example.com/"):]
+	})
+
+	tt.Equal(`@import "local-css/reset.css";
+@import url('local-theme.css') screen;
+.logo { background: url(local-img/logo.png) }
+.icon { background: url("data:image/png;base64,AAAA") }`, css)
+	tt.Equal(map[string]bool{
+		"https://example.com/css/reset.css": true,
+		"https://example.com/theme.css":     true,
+		"https://example.com/img/logo.png":  false,
+	}, saved)
+}
+
+func TestFillSnapshot(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tt.Equal(`a"b`, snapshotEscape(`a"b`, 0))
+	tt.Equal(`a&amp;quot;b`, snapshotEscape(`a"b`, 2))
+
+	html := fillSnapshot(
+		`<img src="__zls_resource_0_0__"><iframe srcdoc="<img src=&quot;__zls_resource_0_1__&quot;><iframe __zls_frame_0_1__></iframe><iframe __zls_frame_1_1__></iframe>"></iframe><img src="__zls_resource_9_0__">`,
+		[]string{"https://example.com/a.png?x=1&y=2"},
+		[]string{` srcdoc="<p title=&quot;a&quot;>b &amp; c</p>"`},
+	)
+	tt.Equal(`<img src="https://example.com/a.png?x=1&y=2"><iframe srcdoc="<img src=&quot;https://example.com/a.png?x=1&amp;y=2&quot;><iframe srcdoc=&quot;<p title=&amp;quot;a&amp;quot;>b &amp;amp; c</p>&quot;></iframe><iframe></iframe>"></iframe><img src="__zls_resource_9_0__">`, html)
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	inner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><p title="q&quot;">a &amp; b</p></body></html>`)
+	}))
+	defer inner.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><iframe srcdoc="<iframe src='`+inner.URL+`'></iframe>"></iframe></body></html>`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		time.Sleep(time.Second)
+		if err := p.SaveSnapshot(dir); err != nil {
+			return err
+		}
+
+		has, err := p.EvalJS(`() => "__zlsSnapshotFrames" in window`)
+		if err != nil {
+			return err
+		}
+		tt.EqualFalse(has.Bool())
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+
+	err = b.Open("file://"+filepath.Join(dir, "index.html"), func(p *Page) error {
+		time.Sleep(time.Second)
+		res, err := p.EvalJS(`() => {
+			const doc = document.querySelector("iframe").contentDocument.querySelector("iframe").contentDocument;
+			const el = doc.querySelector("p");
+			return el.title + "|" + el.textContent;
+		}`)
+		if err != nil {
+			return err
+		}
+		tt.Equal(`q"|a & b`, res.Str())
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+}
@@ -57,6 +57,14 @@ func New(opts ...func(o *Options)) (browser *Browser, err error) {
 
 	browser.Client().EnableCookie(true)
 
+	if p := browser.options.Politeness; p != nil {
+		p.mu.Lock()
+		if p.client == nil {
+			p.client = browser.client
+		}
+		p.mu.Unlock()
+	}
+
 	browser.canUserDir = browser.options.UserMode || browser.options.UserDataDir != ""
 
 	if err := browser.init(); err != nil {
@@ -0,0 +1,27 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// newTestBrowser 启动测试用的浏览器，没有安装浏览器时跳过测试
+func newTestBrowser(t *testing.T, opts ...func(o *Options)) *Browser {
+	bin, ok := launcher.LookPath()
+	if !ok {
+		t.Skip("browser not found")
+	}
+
+	b, err := New(append([]func(o *Options){func(o *Options) {
+		o.Bin = bin
+	}}, opts...)...)
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() {
+		_ = b.Close()
+		b.Cleanup()
+	})
+	return b
+}
@@ -0,0 +1,444 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sohaha/zlsgo/zarray"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/zlsgo/browser"
+)
+
+var (
+	// ErrSkipLinks process 返回该错误时不跟进当前页面的链接
+	ErrSkipLinks = errors.New("crawler: skip links")
+	// ErrStop process 返回该错误时停止抓取，未抓取的地址会保存到状态文件
+	ErrStop = errors.New("crawler: stop")
+)
+
+// Options 抓取选项
+type Options struct {
+	// Fetcher 抓取器，默认使用 Browsers 创建 BrowserFetcher
+	Fetcher Fetcher
+	// Priority 计算地址的优先级，配合 Priority 策略使用
+	Priority func(r *Request) int
+	// Stop 每个页面处理后调用，返回 true 时停止抓取
+	Stop func(s Stats) bool
+	// OnError 抓取或处理失败时调用
+	OnError func(r *Request, err error)
+	// StateFile 状态文件，暂停、停止时保存待抓取队列及已发现的地址，创建时自动恢复
+	StateFile string
+	Browsers  []*browser.Browser
+	// Domains 允许抓取的域名，支持 *.example.com，默认为种子地址的域名
+	Domains []string
+	// Include 地址需要匹配其中之一，支持 * 通配符
+	Include []string
+	// Exclude 匹配的地址不抓取，支持 * 通配符
+	Exclude []string
+	// SkipExtensions 不抓取的文件扩展名，默认为 SkipExtensions
+	SkipExtensions []string
+	// Workers 并发数，默认每个浏览器 2 个
+	Workers int
+	// MaxDepth 最大深度，种子地址深度为 0，0 为不限制
+	MaxDepth int
+	// MaxPages 最多抓取的页面数，0 为不限制
+	MaxPages int
+	Strategy Strategy
+}
+
+// Stats 抓取统计
+type Stats struct {
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+	Pending   int `json:"pending"`
+	InFlight  int `json:"inFlight"`
+	Seen      int `json:"seen"`
+}
+
+// Crawler 站点抓取器
+type Crawler struct {
+	frontier  *frontier
+	cond      *sync.Cond
+	seen      map[string]struct{}
+	inflight  map[*Request]struct{}
+	seedHosts []string
+	o         Options
+	stats     Stats
+	mu        sync.Mutex
+	paused    bool
+	stopped   bool
+}
+
+type crawlerState struct {
+	Pending []*Request `json:"pending"`
+	Seen    []string   `json:"seen"`
+	Stats   Stats      `json:"stats"`
+	Hosts   []string   `json:"hosts"`
+}
+
+// New 创建抓取器，StateFile 存在时恢复上次的状态
+func New(opts ...func(o *Options)) (*Crawler, error) {
+	o := zutil.Optional(Options{SkipExtensions: SkipExtensions}, opts...)
+	if o.Fetcher == nil {
+		if len(o.Browsers) == 0 {
+			return nil, errors.New("crawler: Browsers or Fetcher is required")
+		}
+		o.Fetcher = &BrowserFetcher{Browsers: o.Browsers}
+	}
+	if o.Workers <= 0 {
+		o.Workers = 2 * len(o.Browsers)
+		if o.Workers == 0 {
+			o.Workers = 2
+		}
+	}
+
+	c := &Crawler{
+		o:        o,
+		frontier: newFrontier(o.Strategy),
+		seen:     make(map[string]struct{}),
+		inflight: make(map[*Request]struct{}),
+	}
+	c.cond = sync.NewCond(&c.mu)
+
+	if o.StateFile != "" && zfile.FileExist(o.StateFile) {
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Add 添加种子地址，已发现过的地址会被忽略
+func (c *Crawler) Add(urls ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range urls {
+		u, err := url.Parse(urls[i])
+		if err != nil {
+			return err
+		}
+		normalized, err := normalize(u)
+		if err != nil {
+			return err
+		}
+
+		host := strings.ToLower(u.Hostname())
+		if !zarray.Contains(c.seedHosts, host) {
+			c.seedHosts = append(c.seedHosts, host)
+		}
+		c.enqueue(&Request{URL: normalized})
+	}
+	c.cond.Broadcast()
+	return nil
+}
+
+func (c *Crawler) enqueue(r *Request) bool {
+	if _, ok := c.seen[r.URL]; ok {
+		return false
+	}
+	c.seen[r.URL] = struct{}{}
+	if c.o.Priority != nil {
+		r.Priority = c.o.Priority(r)
+	}
+	c.frontier.push(r)
+	return true
+}
+
+// allow 链接是否在抓取范围内
+func (c *Crawler) allow(u *url.URL, raw string, depth int) bool {
+	if c.o.MaxDepth > 0 && depth > c.o.MaxDepth {
+		return false
+	}
+	if hasExtension(u, c.o.SkipExtensions) {
+		return false
+	}
+
+	domains := c.o.Domains
+	if len(domains) == 0 {
+		domains = c.seedHosts
+	}
+	host := strings.ToLower(u.Hostname())
+	inDomain := false
+	for i := range domains {
+		if matchDomain(host, domains[i]) {
+			inDomain = true
+			break
+		}
+	}
+	if !inDomain {
+		return false
+	}
+
+	for i := range c.o.Exclude {
+		if zstring.Match(raw, c.o.Exclude[i]) {
+			return false
+		}
+	}
+	if len(c.o.Include) == 0 {
+		return true
+	}
+	for i := range c.o.Include {
+		if zstring.Match(raw, c.o.Include[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 开始抓取直到队列为空、达到限制、调用 Stop 或 ctx 结束，
+// process 处理每个页面，返回 ErrSkipLinks 不跟进链接，返回 ErrStop 停止抓取
+func (c *Crawler) Run(ctx context.Context, process func(res *Response) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.mu.Lock()
+	c.stopped = false
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		c.cond.Broadcast()
+		c.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.o.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				r, ok := c.next(ctx)
+				if !ok {
+					return
+				}
+				links, err := c.fetch(ctx, r, process)
+				c.done(ctx, r, links, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.frontier.Len() == 0 {
+		if c.o.StateFile != "" {
+			_ = os.Remove(c.o.StateFile)
+		}
+	} else if err := c.save(); err != nil {
+		return err
+	}
+
+	if !c.stopped && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (c *Crawler) fetch(ctx context.Context, r *Request, process func(res *Response) error) (links []string, err error) {
+	follow := true
+	err = c.o.Fetcher.Fetch(ctx, r, func(res *Response) error {
+		err := process(res)
+		if errors.Is(err, ErrSkipLinks) {
+			follow = false
+			return nil
+		}
+		if err != nil && !errors.Is(err, ErrStop) {
+			return err
+		}
+
+		base, _ := url.Parse(r.URL)
+		if res.FinalURL != "" && res.FinalURL != r.URL {
+			if final, e := url.Parse(res.FinalURL); e == nil {
+				base = final
+			}
+			if final, e := Normalize(res.FinalURL); e == nil {
+				c.mu.Lock()
+				c.seen[final] = struct{}{}
+				c.mu.Unlock()
+			}
+		}
+		for i := range res.Links {
+			if link, e := resolve(base, res.Links[i]); e == nil {
+				links = append(links, link)
+			}
+		}
+		return err
+	})
+	if !follow {
+		links = nil
+	}
+	return links, err
+}
+
+func (c *Crawler) next(ctx context.Context) (*Request, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil || c.stopped || c.limited() {
+			return nil, false
+		}
+		if !c.paused {
+			if c.frontier.Len() > 0 {
+				r := c.frontier.pop()
+				c.inflight[r] = struct{}{}
+				return r, true
+			}
+			if len(c.inflight) == 0 {
+				return nil, false
+			}
+		}
+		c.cond.Wait()
+	}
+}
+
+func (c *Crawler) limited() bool {
+	return c.o.MaxPages > 0 && c.stats.Processed+c.stats.Failed+len(c.inflight) >= c.o.MaxPages
+}
+
+func (c *Crawler) done(ctx context.Context, r *Request, links []string, err error) {
+	if err != nil && !errors.Is(err, ErrStop) && ctx.Err() != nil {
+		// 抓取因 Run 结束而中断，放回队列以便下次继续
+		c.mu.Lock()
+		delete(c.inflight, r)
+		c.frontier.push(r)
+		c.cond.Broadcast()
+		c.mu.Unlock()
+		return
+	}
+
+	if err != nil && !errors.Is(err, ErrStop) && c.o.OnError != nil {
+		c.o.OnError(r, err)
+	}
+
+	c.mu.Lock()
+	delete(c.inflight, r)
+	if err != nil && !errors.Is(err, ErrStop) {
+		c.stats.Failed++
+	} else {
+		c.stats.Processed++
+	}
+
+	for _, link := range links {
+		if _, ok := c.seen[link]; ok {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil || !c.allow(u, link, r.Depth+1) {
+			continue
+		}
+		c.enqueue(&Request{URL: link, Referer: r.URL, Depth: r.Depth + 1})
+	}
+	stats := c.statsLocked()
+	c.cond.Broadcast()
+	c.mu.Unlock()
+
+	if errors.Is(err, ErrStop) || (c.o.Stop != nil && c.o.Stop(stats)) {
+		c.Stop()
+	}
+}
+
+// Pause 暂停抓取，正在抓取的页面会继续完成，设置了 StateFile 时保存状态
+func (c *Crawler) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.paused = true
+	if c.o.StateFile != "" {
+		return c.save()
+	}
+	return nil
+}
+
+// Resume 恢复抓取
+func (c *Crawler) Resume() {
+	c.mu.Lock()
+	c.paused = false
+	c.cond.Broadcast()
+	c.mu.Unlock()
+}
+
+// Stop 停止抓取，Run 会在正在抓取的页面完成后返回
+func (c *Crawler) Stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.cond.Broadcast()
+	c.mu.Unlock()
+}
+
+// Stats 抓取统计
+func (c *Crawler) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statsLocked()
+}
+
+func (c *Crawler) statsLocked() Stats {
+	s := c.stats
+	s.Pending = c.frontier.Len()
+	s.InFlight = len(c.inflight)
+	s.Seen = len(c.seen)
+	return s
+}
+
+// save 保存状态，正在抓取的地址也会保存到队列中以便恢复后重新抓取
+func (c *Crawler) save() error {
+	if c.o.StateFile == "" {
+		return nil
+	}
+
+	pending := make([]*Request, 0, len(c.inflight)+c.frontier.Len())
+	for r := range c.inflight {
+		pending = append(pending, r)
+	}
+	pending = append(pending, c.frontier.pending()...)
+
+	seen := make([]string, 0, len(c.seen))
+	for u := range c.seen {
+		seen = append(seen, u)
+	}
+
+	data, err := json.Marshal(crawlerState{
+		Pending: pending,
+		Seen:    seen,
+		Stats:   c.stats,
+		Hosts:   c.seedHosts,
+	})
+	if err != nil {
+		return err
+	}
+	return zfile.WriteFile(c.o.StateFile, data)
+}
+
+func (c *Crawler) load() error {
+	data, err := zfile.ReadFile(c.o.StateFile)
+	if err != nil {
+		return err
+	}
+
+	var state crawlerState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	for i := range state.Seen {
+		c.seen[state.Seen[i]] = struct{}{}
+	}
+	for i := range state.Pending {
+		c.frontier.push(state.Pending[i])
+	}
+	c.stats = state.Stats
+	c.seedHosts = state.Hosts
+	return nil
+}
@@ -0,0 +1,263 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+var hrefRegexp = regexp.MustCompile(`href="([^"]*)"`)
+
+// httpFetcher 不依赖浏览器的抓取器，用于测试
+type httpFetcher struct{}
+
+func (httpFetcher) Fetch(ctx context.Context, req *Request, fn func(res *Response) error) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	res := &Response{Request: req, FinalURL: resp.Request.URL.String()}
+	for _, m := range hrefRegexp.FindAllStringSubmatch(string(body), -1) {
+		res.Links = append(res.Links, m[1])
+	}
+	return fn(res)
+}
+
+func newSite() *httptest.Server {
+	pages := map[string]string{
+		"/":      `<a href="/a">a</a> <a href="b?utm_source=x">b</a> <a href="https://example.com/">ext</a> <a href="/file.pdf">pdf</a>`,
+		"/a":     `<a href="/a/c#top">c</a> <a href="/">home</a>`,
+		"/b":     `<a href="/a">a</a> <a href="/old">old</a>`,
+		"/a/c":   `<a href="./d">d</a>`,
+		"/a/d":   `end`,
+		"/new":   `<a href="/">home</a>`,
+		"/broke": ``,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "<html><body>"+body+"</body></html>")
+	}))
+}
+
+func crawl(t *testing.T, c *Crawler) []string {
+	var (
+		mu      sync.Mutex
+		visited []string
+	)
+	err := c.Run(context.Background(), func(res *Response) error {
+		mu.Lock()
+		visited = append(visited, res.URL)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(visited)
+	return visited
+}
+
+// cancelFetcher 抓取到指定地址时取消 Run
+type cancelFetcher struct {
+	cancel context.CancelFunc
+	at     string
+}
+
+func (f cancelFetcher) Fetch(ctx context.Context, req *Request, fn func(res *Response) error) error {
+	if req.URL == f.at {
+		f.cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return httpFetcher{}.Fetch(ctx, req, fn)
+}
+
+func TestNormalize(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	for raw, expected := range map[string]string{
+		"HTTP://Example.COM:80":                     "http://example.com/",
+		"https://example.com:443/a/../b/./c/":       "https://example.com/b/c/",
+		"https://example.com/?b=2&a=1&utm_source=x": "https://example.com/?a=1&b=2",
+		"https://example.com/a?#top":                "https://example.com/a",
+		"https://example.com:8080/%E4%B8%AD":        "https://example.com:8080/%E4%B8%AD",
+	} {
+		u, err := Normalize(raw)
+		tt.NoError(err)
+		tt.Equal(expected, u)
+	}
+
+	_, err := Normalize("mailto:a@example.com")
+	tt.EqualTrue(err != nil)
+
+	tt.EqualTrue(matchDomain("a.example.com", "*.example.com"))
+	tt.EqualTrue(matchDomain("example.com", "*.example.com"))
+	tt.EqualFalse(matchDomain("badexample.com", "*.example.com"))
+}
+
+func TestFrontier(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	order := func(s Strategy) (urls []string) {
+		f := newFrontier(s)
+		f.push(&Request{URL: "a", Depth: 1})
+		f.push(&Request{URL: "b", Depth: 0, Priority: 1})
+		f.push(&Request{URL: "c", Depth: 1, Priority: 5})
+		f.push(&Request{URL: "d", Depth: 0})
+		for _, r := range f.pending() {
+			urls = append(urls, r.URL)
+		}
+		return
+	}
+	tt.Equal([]string{"b", "d", "a", "c"}, order(BFS))
+	tt.Equal([]string{"c", "a", "d", "b"}, order(DFS))
+	tt.Equal([]string{"c", "b", "a", "d"}, order(Priority))
+}
+
+func TestCrawler(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	site := newSite()
+	defer site.Close()
+
+	var (
+		mu     sync.Mutex
+		failed []string
+	)
+	c, err := New(func(o *Options) {
+		o.Fetcher = httpFetcher{}
+		o.Workers = 3
+		o.OnError = func(r *Request, err error) {
+			mu.Lock()
+			failed = append(failed, r.URL)
+			mu.Unlock()
+		}
+	})
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL, site.URL+"/broke", site.URL+"/missing"))
+
+	visited := crawl(t, c)
+	tt.Equal([]string{
+		site.URL + "/", site.URL + "/a", site.URL + "/a/c", site.URL + "/a/d",
+		site.URL + "/b", site.URL + "/broke", site.URL + "/old",
+	}, visited)
+	tt.Equal([]string{site.URL + "/missing"}, failed)
+
+	stats := c.Stats()
+	tt.Equal(7, stats.Processed)
+	tt.Equal(1, stats.Failed)
+	tt.Equal(0, stats.Pending)
+
+	c, err = New(func(o *Options) {
+		o.Fetcher = httpFetcher{}
+		o.MaxDepth = 1
+		o.Exclude = []string{"*/b"}
+	})
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL))
+	tt.Equal([]string{site.URL + "/", site.URL + "/a"}, crawl(t, c))
+}
+
+func TestCrawlerResume(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	site := newSite()
+	defer site.Close()
+
+	state := filepath.Join(t.TempDir(), "state.json")
+	opts := func(o *Options) {
+		o.Fetcher = httpFetcher{}
+		o.Workers = 1
+		o.StateFile = state
+		o.Stop = func(s Stats) bool {
+			return s.Processed >= 2
+		}
+	}
+
+	c, err := New(opts)
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL))
+	first := crawl(t, c)
+	tt.Equal(2, len(first))
+
+	c, err = New(opts, func(o *Options) {
+		o.Stop = nil
+	})
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL))
+	second := crawl(t, c)
+
+	all := append(first, second...)
+	sort.Strings(all)
+	tt.Equal([]string{
+		site.URL + "/", site.URL + "/a", site.URL + "/a/c", site.URL + "/a/d",
+		site.URL + "/b", site.URL + "/old",
+	}, all)
+	tt.Equal(6, c.Stats().Processed)
+
+	state = filepath.Join(t.TempDir(), "state.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var failed []string
+	c, err = New(func(o *Options) {
+		o.Fetcher = cancelFetcher{cancel: cancel, at: site.URL + "/a"}
+		o.Workers = 1
+		o.StateFile = state
+		o.OnError = func(r *Request, err error) {
+			failed = append(failed, r.URL)
+		}
+	})
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL))
+	var visited []string
+	err = c.Run(ctx, func(res *Response) error {
+		visited = append(visited, res.URL)
+		return nil
+	})
+	tt.Equal(context.Canceled, err)
+	tt.Equal([]string{site.URL + "/"}, visited)
+	tt.Equal(0, len(failed))
+	tt.Equal(0, c.Stats().Failed)
+
+	c, err = New(opts, func(o *Options) {
+		o.StateFile = state
+		o.Stop = nil
+	})
+	tt.NoError(err)
+	tt.NoError(c.Add(site.URL))
+	all = append(visited, crawl(t, c)...)
+	sort.Strings(all)
+	tt.Equal([]string{
+		site.URL + "/", site.URL + "/a", site.URL + "/a/c", site.URL + "/a/d",
+		site.URL + "/b", site.URL + "/old",
+	}, all)
+}
@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/zlsgo/browser"
+)
+
+// Response 抓取结果
+type Response struct {
+	*Request
+	// Page 浏览器页面，process 返回后页面会被关闭，非浏览器抓取时为空
+	Page *browser.Page
+	// FinalURL 跳转后的最终地址
+	FinalURL string
+	// Links 页面中发现的链接，process 可以修改该列表以控制后续抓取
+	Links []string
+}
+
+// Fetcher 抓取器，打开地址并在页面可用时调用 fn
+type Fetcher interface {
+	Fetch(ctx context.Context, req *Request, fn func(res *Response) error) error
+}
+
+// BrowserFetcher 使用 Browser.Open 渲染页面，多个浏览器时轮流使用
+type BrowserFetcher struct {
+	// PageOptions 页面选项
+	PageOptions func(o *browser.PageOptions)
+	// Ready 页面打开后、提取链接前执行，例如等待 DOM 稳定
+	Ready func(p *browser.Page) error
+	// LinkSelector 链接选择器，默认 a[href]
+	LinkSelector string
+	Browsers     []*browser.Browser
+	next         uint32
+}
+
+var _ Fetcher = (*BrowserFetcher)(nil)
+
+var jsLinks = `(selector) => Array.from(document.querySelectorAll(selector))
+	.map((a) => a.href instanceof SVGAnimatedString ? a.href.baseVal : (a.href || a.getAttribute("href") || ""))
+	.filter(Boolean)`
+
+// Fetch 打开页面并提取链接
+func (f *BrowserFetcher) Fetch(ctx context.Context, req *Request, fn func(res *Response) error) error {
+	if len(f.Browsers) == 0 {
+		return errors.New("crawler: no browser")
+	}
+
+	selector := f.LinkSelector
+	if selector == "" {
+		selector = "a[href]"
+	}
+
+	b := f.Browsers[int(atomic.AddUint32(&f.next, 1)-1)%len(f.Browsers)]
+	opts := make([]func(o *browser.PageOptions), 0, 2)
+	if f.PageOptions != nil {
+		opts = append(opts, f.PageOptions)
+	}
+	opts = append(opts, func(o *browser.PageOptions) {
+		o.Ctx = ctx
+	})
+
+	return b.Open(req.URL, func(p *browser.Page) error {
+		if f.Ready != nil {
+			if err := f.Ready(p); err != nil {
+				return err
+			}
+		}
+
+		res := &Response{Request: req, Page: p, FinalURL: req.URL}
+		if info, err := p.ROD().Info(); err == nil {
+			res.FinalURL = info.URL
+		}
+
+		links, err := p.EvalJS(jsLinks, selector)
+		if err != nil {
+			return err
+		}
+		for _, l := range links.Arr() {
+			res.Links = append(res.Links, l.Str())
+		}
+
+		return fn(res)
+	}, opts...)
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/sohaha/zlsgo"
+	"github.com/zlsgo/browser"
+)
+
+func TestBrowserFetcher(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	bin, ok := launcher.LookPath()
+	if !ok {
+		t.Skip("browser not found")
+	}
+	b, err := browser.New(func(o *browser.Options) {
+		o.Bin = bin
+	})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer func() {
+		_ = b.Close()
+		b.Cleanup()
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(w, `<a href="/a">a</a><a href="/b">b</a>`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	f := &BrowserFetcher{Browsers: []*browser.Browser{b}}
+	var links []string
+	err = f.Fetch(context.Background(), &Request{URL: srv.URL + "/"}, func(res *Response) error {
+		links = res.Links
+		tt.Equal(srv.URL+"/", res.FinalURL)
+		return nil
+	})
+	tt.NoError(err)
+	tt.Equal([]string{srv.URL + "/a", srv.URL + "/b"}, links)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = f.Fetch(ctx, &Request{URL: srv.URL + "/slow"}, func(res *Response) error {
+		return nil
+	})
+	tt.EqualTrue(err != nil)
+	tt.EqualTrue(time.Since(start) < 5*time.Second)
+}
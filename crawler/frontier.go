@@ -0,0 +1,95 @@
+package crawler
+
+import "container/heap"
+
+// Strategy 抓取顺序
+type Strategy uint8
+
+const (
+	// BFS 广度优先，深度小的先抓取
+	BFS Strategy = iota
+	// DFS 深度优先，最后发现的先抓取
+	DFS
+	// Priority 按 Request.Priority 从高到低抓取，相同优先级按发现顺序
+	Priority
+)
+
+// Request 待抓取的地址
+type Request struct {
+	URL      string `json:"url"`
+	Referer  string `json:"referer,omitempty"`
+	Depth    int    `json:"depth"`
+	Priority int    `json:"priority,omitempty"`
+	seq      uint64
+}
+
+// frontier 待抓取队列
+type frontier struct {
+	items    []*Request
+	strategy Strategy
+	seq      uint64
+}
+
+func newFrontier(strategy Strategy) *frontier {
+	return &frontier{strategy: strategy}
+}
+
+func (f *frontier) Len() int {
+	return len(f.items)
+}
+
+func (f *frontier) Less(i, j int) bool {
+	a, b := f.items[i], f.items[j]
+	switch f.strategy {
+	case DFS:
+		if a.Depth != b.Depth {
+			return a.Depth > b.Depth
+		}
+		return a.seq > b.seq
+	case Priority:
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+	default:
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+	}
+	return a.seq < b.seq
+}
+
+func (f *frontier) Swap(i, j int) {
+	f.items[i], f.items[j] = f.items[j], f.items[i]
+}
+
+func (f *frontier) Push(x interface{}) {
+	f.items = append(f.items, x.(*Request))
+}
+
+func (f *frontier) Pop() interface{} {
+	n := len(f.items) - 1
+	r := f.items[n]
+	f.items[n] = nil
+	f.items = f.items[:n]
+	return r
+}
+
+func (f *frontier) push(r *Request) {
+	f.seq++
+	r.seq = f.seq
+	heap.Push(f, r)
+}
+
+func (f *frontier) pop() *Request {
+	return heap.Pop(f).(*Request)
+}
+
+// pending 按抓取顺序返回队列中的地址
+func (f *frontier) pending() []*Request {
+	c := &frontier{strategy: f.strategy, items: append([]*Request(nil), f.items...)}
+	list := make([]*Request, 0, len(c.items))
+	for c.Len() > 0 {
+		list = append(list, c.pop())
+	}
+	return list
+}
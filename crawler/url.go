@@ -0,0 +1,123 @@
+package crawler
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+// TrackingParams 规范化地址时默认去掉的跟踪参数，支持 * 通配符
+var TrackingParams = []string{"utm_*", "fbclid", "gclid", "msclkid", "yclid", "spm", "_ga"}
+
+// SkipExtensions 默认不抓取的文件扩展名
+var SkipExtensions = []string{
+	".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg", ".ico", ".bmp", ".avif",
+	".css", ".js", ".mjs", ".map", ".json", ".xml",
+	".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
+	".zip", ".rar", ".7z", ".gz", ".tar", ".exe", ".dmg", ".apk", ".iso",
+	".mp3", ".mp4", ".avi", ".mov", ".webm", ".wav", ".flac",
+	".woff", ".woff2", ".ttf", ".otf", ".eot",
+}
+
+var errScheme = errors.New("unsupported scheme")
+
+// Normalize 规范化地址，用于去重：
+// 小写协议及域名，去掉默认端口、锚点、跟踪参数及路径中的 . 和 ..，查询参数按名称排序
+func Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	return normalize(u)
+}
+
+func resolve(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", err
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return normalize(u)
+}
+
+func normalize(u *url.URL) (string, error) {
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errScheme
+	}
+	if u.Host == "" {
+		return "", errors.New("missing host")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	if clean, err := url.Parse(cleaned); err == nil {
+		u.Path, u.RawPath = clean.Path, clean.RawPath
+	}
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for name := range q {
+			for _, pattern := range TrackingParams {
+				if zstring.Match(name, pattern, true) {
+					q.Del(name)
+					break
+				}
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	u.ForceQuery = false
+	u.Fragment, u.RawFragment = "", ""
+
+	return u.String(), nil
+}
+
+// matchDomain 域名是否匹配，*.example.com 同时匹配 example.com 及其子域名
+func matchDomain(host, domain string) bool {
+	domain = strings.ToLower(domain)
+	if host == domain {
+		return true
+	}
+	if strings.HasPrefix(domain, "*.") {
+		return host == domain[2:] || strings.HasSuffix(host, domain[1:])
+	}
+	return strings.Contains(domain, "*") && zstring.Match(host, domain)
+}
+
+func hasExtension(u *url.URL, exts []string) bool {
+	ext := strings.ToLower(path.Ext(u.Path))
+	if ext == "" {
+		return false
+	}
+	for i := range exts {
+		if ext == exts[i] {
+			return true
+		}
+	}
+	return false
+}
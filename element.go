@@ -11,6 +11,9 @@ import (
 type Element struct {
 	element *rod.Element
 	page    *Page
+	force   bool
+	// located 由定位器创建，元素被移除时不再等待，交由定位器重新查找
+	located bool
 }
 
 type Elements []*Element
@@ -59,6 +62,8 @@ func (e *Element) Timeout(d ...time.Duration) *Element {
 	return &Element{
 		element: element,
 		page:    e.page,
+		force:   e.force,
+		located: e.located,
 	}
 }
 
@@ -82,12 +87,14 @@ func (e *Element) Frame() (*Page, error) {
 		return nil, err
 	}
 
-	return e.page.FromROD(frame), nil
+	p := e.page.FromROD(frame)
+	p.owner = e
+	return p, nil
 }
 
 // HasElement 检查元素是否存在，不会等待元素出现
 func (e *Element) HasElement(selector string) (bool, *Element) {
-	has, ele, _ := e.element.Has(selector)
+	has, ele := hasElement(nil, e.element, selector)
 	if !has {
 		return false, nil
 	}
@@ -104,7 +111,7 @@ func (e *Element) Element(selector string, jsRegex ...string) (element *Element,
 		relm *rod.Element
 	)
 	if len(jsRegex) == 0 {
-		relm, err = e.element.Element(selector)
+		relm, err = queryElement(nil, e.element, selector)
 	} else {
 		relm, err = e.element.ElementR(selector, jsRegex[0])
 	}
@@ -128,12 +135,12 @@ func (e *Element) MustElement(selector string, jsRegex ...string) *Element {
 }
 
 func (e *Element) Elements(selector string) (elements Elements, has bool) {
-	_, err := e.element.Element(selector)
+	_, err := queryElement(nil, e.element, selector)
 	if err != nil {
 		return Elements{}, false
 	}
 
-	es, _ := e.element.Elements(selector)
+	es, _ := queryElements(nil, e.element, selector)
 	has = len(es) > 0
 	elements = make(Elements, 0, len(es))
 	for i := range es {
@@ -41,8 +41,12 @@ func (e *Element) FindTextInputElement(selector ...string) (element *Element, ha
 	return nil, false
 }
 
-// InputText 输入文字
+// InputText 输入文字，输入前会等待元素可见、可用且可编辑
 func (e *Element) InputText(text string, clear ...bool) error {
+	if err := e.WaitActionable(inputChecks...); err != nil {
+		return err
+	}
+
 	if len(clear) > 0 && clear[0] {
 		_ = e.element.SelectAllText()
 	}
@@ -55,7 +59,7 @@ func (e *Element) InputEnter(presskeys ...input.Key) error {
 	return e.page.page.KeyActions().Press(presskeys...).Type(input.Enter).Do()
 }
 
-// Click 点击元素
+// Click 点击元素，点击前会等待元素可见、稳定、可用且未被遮挡
 func (e *Element) Click(button ...proto.InputMouseButton) error {
 	var b proto.InputMouseButton
 	if len(button) > 0 {
@@ -64,6 +68,10 @@ func (e *Element) Click(button ...proto.InputMouseButton) error {
 		b = proto.InputMouseButtonLeft
 	}
 
+	if err := e.WaitActionable(clickChecks...); err != nil {
+		return err
+	}
+
 	return e.element.Click(b, 1)
 }
 
@@ -0,0 +1,412 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/ztype"
+)
+
+// ExtractError 提取失败的字段及选择器
+type ExtractError struct {
+	Err      error
+	Field    string
+	Selector string
+}
+
+func (e *ExtractError) Error() string {
+	if e.Selector == "" {
+		return fmt.Sprintf("extract %s: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("extract %s (%s): %v", e.Field, e.Selector, e.Err)
+}
+
+func (e *ExtractError) Unwrap() error {
+	return e.Err
+}
+
+// ErrExtractNotFound 必填字段的元素不存在
+var ErrExtractNotFound = errors.New("element not found")
+
+var (
+	extractRegexps sync.Map
+	extractNumber  = regexp.MustCompile(`-?\d[\d,]*(\.\d+)?|-?\.\d+`)
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// Extract 按结构体标签从页面提取数据，v 需要为结构体指针，支持的标签：
+//
+//	sel     选择器，结构体字段为作用域元素，切片字段匹配所有元素
+//	attr    取值方式，text（默认）、html 或属性名，属性不存在时读取同名 property
+//	re      正则表达式，有分组时取第一个分组
+//	default 元素不存在或值为空时使用的默认值
+//	opt     为 true 时元素不存在不报错
+//	layout  时间格式，默认依次尝试 RFC3339、2006-01-02 15:04:05、2006-01-02
+func (page *Page) Extract(v interface{}) error {
+	return extract(page, nil, v)
+}
+
+// Extract 以当前元素为作用域按结构体标签提取数据，标签说明见 Page.Extract
+func (e *Element) Extract(v interface{}) error {
+	return extract(e.page, e.element, v)
+}
+
+func extract(page *Page, scope *rod.Element, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("extract requires a non-nil pointer to struct")
+	}
+
+	x := &extractor{page: page}
+	return x.fillStruct(rv.Elem(), scope, rv.Elem().Type().Name())
+}
+
+type extractor struct {
+	page *Page
+}
+
+type extractTag struct {
+	sel      string
+	attr     string
+	re       string
+	def      string
+	layout   string
+	hasDef   bool
+	optional bool
+}
+
+func parseExtractTag(f reflect.StructField) (t extractTag, ok bool) {
+	t.sel, ok = f.Tag.Lookup("sel")
+	attr, hasAttr := f.Tag.Lookup("attr")
+	t.re = f.Tag.Get("re")
+	t.def, t.hasDef = f.Tag.Lookup("default")
+	t.layout = f.Tag.Get("layout")
+	t.optional, _ = strconv.ParseBool(f.Tag.Get("opt"))
+	t.attr = strings.TrimSpace(attr)
+	if t.attr == "" {
+		t.attr = "text"
+	}
+
+	return t, ok || hasAttr || t.re != "" || t.hasDef
+}
+
+func (x *extractor) fillStruct(rv reflect.Value, scope *rod.Element, path string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		field := f.Name
+		if path != "" {
+			field = path + "." + f.Name
+		}
+
+		tag, ok := parseExtractTag(f)
+		fv := rv.Field(i)
+		if !ok {
+			if isExtractStruct(f.Type) {
+				if err := x.fillStructValue(fv, scope, field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := x.fillField(fv, scope, tag, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (x *extractor) fillField(fv reflect.Value, scope *rod.Element, tag extractTag, field string) error {
+	wrap := func(err error) error {
+		var e *ExtractError
+		if errors.As(err, &e) {
+			return err
+		}
+		return &ExtractError{Field: field, Selector: tag.sel, Err: err}
+	}
+
+	t := fv.Type()
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		return x.fillSlice(fv, scope, tag, field, wrap)
+	}
+
+	ele := scope
+	if tag.sel != "" {
+		has, e := hasElement(x.page.page, scope, tag.sel)
+		if !has {
+			if tag.hasDef {
+				return wrap(setExtractValue(fv, tag.def, tag.layout))
+			}
+			if tag.optional {
+				return nil
+			}
+			return wrap(ErrExtractNotFound)
+		}
+		ele = e
+	} else if ele == nil {
+		e, err := x.page.page.Element("html")
+		if err != nil {
+			return wrap(err)
+		}
+		ele = e
+	}
+
+	if isExtractStruct(t) {
+		return x.fillStructValue(fv, ele, field)
+	}
+
+	value, err := x.value(ele, tag)
+	if err != nil {
+		return wrap(err)
+	}
+	if value == "" && tag.hasDef {
+		value = tag.def
+	}
+	if value == "" && tag.optional {
+		return nil
+	}
+
+	return wrap(setExtractValue(fv, value, tag.layout))
+}
+
+func (x *extractor) fillSlice(fv reflect.Value, scope *rod.Element, tag extractTag, field string, wrap func(error) error) error {
+	if tag.sel == "" {
+		return wrap(errors.New("slice field requires sel tag"))
+	}
+
+	es, err := queryElements(x.page.page, scope, tag.sel)
+	if err != nil {
+		return wrap(err)
+	}
+	if len(es) == 0 && !tag.optional && !tag.hasDef {
+		return wrap(ErrExtractNotFound)
+	}
+
+	t := fv.Type()
+	list := reflect.MakeSlice(t, 0, len(es))
+	for i := range es {
+		item := reflect.New(t.Elem()).Elem()
+		itemField := fmt.Sprintf("%s[%d]", field, i)
+		if isExtractStruct(t.Elem()) {
+			if err = x.fillStructValue(item, es[i], itemField); err != nil {
+				return err
+			}
+		} else {
+			value, err := x.value(es[i], tag)
+			if err != nil {
+				return &ExtractError{Field: itemField, Selector: tag.sel, Err: err}
+			}
+			if value == "" && tag.optional {
+				continue
+			}
+			if err = setExtractValue(item, value, tag.layout); err != nil {
+				return &ExtractError{Field: itemField, Selector: tag.sel, Err: err}
+			}
+		}
+		list = reflect.Append(list, item)
+	}
+
+	fv.Set(list)
+	return nil
+}
+
+func (x *extractor) fillStructValue(fv reflect.Value, scope *rod.Element, field string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	return x.fillStruct(fv, scope, field)
+}
+
+func (x *extractor) value(ele *rod.Element, tag extractTag) (value string, err error) {
+	switch tag.attr {
+	case "text":
+		value, err = ele.Text()
+	case "html":
+		var res *proto.RuntimeRemoteObject
+		res, err = ele.Eval(`() => this.innerHTML`)
+		if err == nil {
+			value = res.Value.Str()
+		}
+	default:
+		var attr *string
+		attr, err = ele.Attribute(tag.attr)
+		if err == nil && attr != nil {
+			value = *attr
+		} else if err == nil {
+			p, e := ele.Property(tag.attr)
+			if e != nil {
+				return "", e
+			}
+			if !p.Nil() {
+				value = p.String()
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value = strings.TrimSpace(value)
+	if tag.re == "" || value == "" {
+		return value, nil
+	}
+
+	re, err := extractRegexp(tag.re)
+	if err != nil {
+		return "", err
+	}
+	m := re.FindStringSubmatch(value)
+	switch {
+	case len(m) == 0:
+		return "", nil
+	case len(m) > 1:
+		return m[1], nil
+	default:
+		return m[0], nil
+	}
+}
+
+func extractRegexp(expr string) (*regexp.Regexp, error) {
+	if re, ok := extractRegexps.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	extractRegexps.Store(expr, re)
+	return re, nil
+}
+
+func isExtractStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+func setExtractValue(fv reflect.Value, value, layout string) error {
+	if fv.Kind() == reflect.Ptr {
+		if value == "" {
+			return nil
+		}
+		v := reflect.New(fv.Type().Elem())
+		if err := setExtractValue(v.Elem(), value, layout); err != nil {
+			return err
+		}
+		fv.Set(v)
+		return nil
+	}
+
+	if fv.Type() == timeType {
+		if value == "" {
+			return nil
+		}
+		t, err := parseExtractTime(value, layout)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		fv.SetBool(ztype.ToBool(value))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := extractNumeric(value)
+		if err != nil {
+			return err
+		}
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(n, 64)
+			if ferr != nil {
+				return err
+			}
+			i = int64(f)
+		}
+		if fv.OverflowInt(i) {
+			return fmt.Errorf("value %q overflows %s", value, fv.Type())
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := extractNumeric(value)
+		if err != nil {
+			return err
+		}
+		u, err := strconv.ParseUint(n, 10, 64)
+		if err != nil {
+			return err
+		}
+		if fv.OverflowUint(u) {
+			return fmt.Errorf("value %q overflows %s", value, fv.Type())
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		n, err := extractNumeric(value)
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		fv.SetBytes([]byte(value))
+	case reflect.Interface:
+		fv.Set(reflect.ValueOf(value))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	return nil
+}
+
+// extractNumeric 从文本中取出第一个数字，去掉千分位，例如 "$1,299.00" 为 "1299.00"
+func extractNumeric(value string) (string, error) {
+	n := extractNumber.FindString(value)
+	if n == "" {
+		return "", fmt.Errorf("no number in %q", value)
+	}
+	return strings.ReplaceAll(n, ",", ""), nil
+}
+
+func parseExtractTime(value, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.ParseInLocation(layout, value, time.Local)
+	}
+
+	var err error
+	for _, l := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(l, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,43 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestSetExtractValue(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	var v struct {
+		Price  float64
+		Stock  int
+		Count  *uint
+		On     bool
+		Date   time.Time
+		Remark string
+	}
+	rv := reflect.ValueOf(&v).Elem()
+
+	tt.NoError(setExtractValue(rv.Field(0), "$1,299.50", ""))
+	tt.Equal(1299.5, v.Price)
+	tt.NoError(setExtractValue(rv.Field(1), "剩余 12 件", ""))
+	tt.Equal(12, v.Stock)
+	tt.NoError(setExtractValue(rv.Field(2), "3", ""))
+	tt.Equal(uint(3), *v.Count)
+	tt.NoError(setExtractValue(rv.Field(3), "true", ""))
+	tt.EqualTrue(v.On)
+	tt.NoError(setExtractValue(rv.Field(4), "2024-05-01", ""))
+	tt.Equal(2024, v.Date.Year())
+	tt.NoError(setExtractValue(rv.Field(4), "01/06/2024", "02/01/2006"))
+	tt.Equal(time.June, v.Date.Month())
+	tt.NoError(setExtractValue(rv.Field(5), "ok", ""))
+	tt.Equal("ok", v.Remark)
+
+	tt.EqualTrue(setExtractValue(rv.Field(1), "none", "") != nil)
+
+	err := &ExtractError{Field: "Product.Price", Selector: ".price", Err: ErrExtractNotFound}
+	tt.Equal("extract Product.Price (.price): element not found", err.Error())
+}
@@ -116,6 +116,36 @@ func (fp *FluentPage) TypeInto(selector, text string) *FluentPage {
 	return fp
 }
 
+// Locate 通过定位器查找元素并返回流式元素操作，定位器在当前页面中查找
+func (fp *FluentPage) Locate(l *Locator) *FluentElement {
+	if fp.err != nil {
+		return &FluentElement{err: fp.err}
+	}
+
+	element, err := l.On(fp.page).Element()
+	return &FluentElement{element: element, err: err}
+}
+
+// ClickLocator 点击定位器匹配的元素，元素在点击前被重新渲染时会重新查找
+func (fp *FluentPage) ClickLocator(l *Locator) *FluentPage {
+	if fp.err != nil {
+		return fp
+	}
+
+	fp.err = l.On(fp.page).Click()
+	return fp
+}
+
+// TypeIntoLocator 在定位器匹配的元素中输入文本，元素在输入前被重新渲染时会重新查找
+func (fp *FluentPage) TypeIntoLocator(l *Locator, text string) *FluentPage {
+	if fp.err != nil {
+		return fp
+	}
+
+	fp.err = l.On(fp.page).InputText(text)
+	return fp
+}
+
 // FillForm 批量填充表单
 func (fp *FluentPage) FillForm(data map[string]string) *FluentPage {
 	if fp.err != nil {
@@ -232,7 +262,10 @@ func (fe *FluentElement) DoubleClick() *FluentElement {
 	if fe.err != nil {
 		return fe
 	}
-	fe.err = fe.element.element.Click(proto.InputMouseButtonLeft, 2)
+	fe.err = fe.element.WaitActionable(clickChecks...)
+	if fe.err == nil {
+		fe.err = fe.element.element.Click(proto.InputMouseButtonLeft, 2)
+	}
 	return fe
 }
 
@@ -303,7 +336,10 @@ func (fe *FluentElement) Hover() *FluentElement {
 	if fe.err != nil {
 		return fe
 	}
-	fe.err = fe.element.element.Hover()
+	fe.err = fe.element.WaitActionable()
+	if fe.err == nil {
+		fe.err = fe.element.element.Hover()
+	}
 	return fe
 }
 
@@ -0,0 +1,290 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+var errFrameNotFound = errors.New("frame not found")
+
+// Frame 框架树节点
+type Frame struct {
+	page        *Page
+	host        *rod.Page
+	Parent      *Frame `json:"-"`
+	ID          proto.PageFrameID
+	ParentID    proto.PageFrameID
+	Name        string
+	URL         string
+	Origin      string
+	Children    []*Frame
+	CrossOrigin bool
+}
+
+// Frames 获取页面的框架树，包含跨进程（跨域）的 iframe
+func (page *Page) Frames() (*Frame, error) {
+	tree, err := proto.PageGetFrameTree{}.Call(page.page)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[proto.PageFrameID]*Frame)
+	root := page.buildFrame(tree.FrameTree, page.page, nil, index)
+
+	targets, err := proto.TargetGetTargets{}.Call(page.browser.Browser)
+	if err != nil {
+		return root, nil
+	}
+
+	pending := make([]*proto.TargetTargetInfo, 0, len(targets.TargetInfos))
+	for _, v := range targets.TargetInfos {
+		if v.Type != "iframe" {
+			continue
+		}
+		pending = append(pending, v)
+	}
+
+	for merged := true; merged && len(pending) > 0; {
+		merged = false
+		rest := pending[:0]
+		for _, v := range pending {
+			if !page.mergeCrossOriginFrame(v, index) {
+				rest = append(rest, v)
+				continue
+			}
+			merged = true
+		}
+		pending = rest
+	}
+
+	return root, nil
+}
+
+func (page *Page) buildFrame(tree *proto.PageFrameTree, host *rod.Page, parent *Frame, index map[proto.PageFrameID]*Frame) *Frame {
+	f := &Frame{
+		page:     page,
+		host:     host,
+		Parent:   parent,
+		ID:       tree.Frame.ID,
+		ParentID: tree.Frame.ParentID,
+		Name:     tree.Frame.Name,
+		URL:      tree.Frame.URL + tree.Frame.URLFragment,
+		Origin:   tree.Frame.SecurityOrigin,
+	}
+	index[f.ID] = f
+
+	for _, child := range tree.ChildFrames {
+		f.Children = append(f.Children, page.buildFrame(child, host, f, index))
+	}
+
+	return f
+}
+
+// mergeCrossOriginFrame 将独立进程的 iframe 合并到框架树中
+func (page *Page) mergeCrossOriginFrame(target *proto.TargetTargetInfo, index map[proto.PageFrameID]*Frame) bool {
+	id := proto.PageFrameID(target.TargetID)
+	if f, ok := index[id]; ok && f.CrossOrigin {
+		return true
+	}
+
+	rp, ok := page.state.frameHost(target.TargetID)
+	if !ok {
+		if !page.ownsFrame(id, index) {
+			return false
+		}
+
+		var err error
+		rp, err = page.browser.Browser.PageFromTarget(target.TargetID)
+		if err != nil {
+			return false
+		}
+		page.state.setFrameHost(page.browser.Browser, target.TargetID, rp)
+	}
+
+	tree, err := proto.PageGetFrameTree{}.Call(rp)
+	if err != nil {
+		return false
+	}
+
+	parent := index[tree.FrameTree.Frame.ParentID]
+	if existing, ok := index[id]; ok {
+		parent = existing.Parent
+	}
+	if parent == nil {
+		return false
+	}
+
+	f := page.buildFrame(tree.FrameTree, rp, parent, index)
+	f.CrossOrigin = true
+
+	for i := range parent.Children {
+		if parent.Children[i].ID == id {
+			parent.Children[i] = f
+			return true
+		}
+	}
+	parent.Children = append(parent.Children, f)
+
+	return true
+}
+
+// ownsFrame 检查 iframe 的宿主元素是否在当前已知的框架中，避免附加到其他标签页的 iframe
+func (page *Page) ownsFrame(id proto.PageFrameID, index map[proto.PageFrameID]*Frame) bool {
+	hosts := make(map[*rod.Page]struct{}, len(index))
+	for _, f := range index {
+		if _, ok := hosts[f.host]; ok {
+			continue
+		}
+		hosts[f.host] = struct{}{}
+		if _, err := (proto.DOMGetFrameOwner{FrameID: id}).Call(f.host); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// frameHost 获取已附加的跨域 iframe 会话
+func (s *pageState) frameHost(id proto.TargetTargetID) (*rod.Page, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rp, ok := s.frames[id]
+	return rp, ok
+}
+
+// setFrameHost 缓存跨域 iframe 会话，页面释放时断开这些会话
+func (s *pageState) setFrameHost(b *rod.Browser, id proto.TargetTargetID, rp *rod.Page) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.frames == nil {
+		s.frames = make(map[proto.TargetTargetID]*rod.Page)
+		s.releases = append(s.releases, func() error {
+			s.mu.Lock()
+			frames := s.frames
+			s.frames = nil
+			s.mu.Unlock()
+
+			for id, rp := range frames {
+				b.RemoveState(id)
+				// iframe 可能已被销毁，忽略断开失败
+				_ = proto.TargetDetachFromTarget{SessionID: rp.SessionID}.Call(b)
+			}
+			return nil
+		})
+	}
+	s.frames[id] = rp
+}
+
+// Find 深度优先查找第一个满足条件的框架
+func (f *Frame) Find(fn func(f *Frame) bool) *Frame {
+	if fn(f) {
+		return f
+	}
+
+	for _, child := range f.Children {
+		if found := child.Find(fn); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// Page 获取框架对应的页面，可用于查找元素、执行脚本及截图
+func (f *Frame) Page() (*Page, error) {
+	if f.Parent == nil {
+		return f.page, nil
+	}
+
+	host := f.Parent.host
+	owner, err := proto.DOMGetFrameOwner{FrameID: f.ID}.Call(host)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerElement, err := host.ElementFromNode(&proto.DOMNode{BackendNodeID: owner.BackendNodeID})
+	if err != nil {
+		return nil, err
+	}
+
+	rp := f.host
+	if !f.CrossOrigin {
+		rp, err = ownerElement.Frame()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := f.page.FromROD(rp)
+	p.owner = &Element{element: ownerElement, page: f.page}
+	return p, nil
+}
+
+// FrameByName 按 name 获取框架页面
+func (page *Page) FrameByName(name string) (*Page, error) {
+	return page.frame(func(f *Frame) bool {
+		return f.Parent != nil && f.Name == name
+	})
+}
+
+// FrameByURL 按 URL 获取框架页面，支持 * 通配符
+func (page *Page) FrameByURL(pattern string) (*Page, error) {
+	return page.frame(func(f *Frame) bool {
+		return f.Parent != nil && zstring.Match(f.URL, pattern)
+	})
+}
+
+// WaitFrame 等待满足条件的框架出现并返回框架页面，未设置超时时等待至页面关闭
+func (page *Page) WaitFrame(fn func(f *Frame) bool, d ...time.Duration) (*Page, error) {
+	ctx := page.page.GetContext()
+	if timeout := page.GetTimeout(d...); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		p, err := page.frame(func(f *Frame) bool {
+			return f.Parent != nil && fn(f)
+		})
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, errFrameNotFound) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (page *Page) frame(fn func(f *Frame) bool) (*Page, error) {
+	root, err := page.Frames()
+	if err != nil {
+		return nil, err
+	}
+
+	f := root.Find(fn)
+	if f == nil {
+		return nil, errFrameNotFound
+	}
+
+	return f.Page()
+}
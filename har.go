@@ -0,0 +1,610 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+var errHARNotStarted = errors.New("har recording has not started")
+
+// HAR HTTP Archive 1.2
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Browser *HARCreator `json:"browser,omitempty"`
+	Pages   []HARPage   `json:"pages"`
+	Entries []HAREntry  `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+}
+
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Cache           struct{}    `json:"cache"`
+	Pageref         string      `json:"pageref,omitempty"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Timings         HARTimings  `json:"timings"`
+	Time            float64     `json:"time"`
+}
+
+type HARRequest struct {
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	RedirectURL string         `json:"redirectURL"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	Status      int            `json:"status"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARCookie struct {
+	Expires  *time.Time `json:"expires,omitempty"`
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HARNameValue `json:"params,omitempty"`
+}
+
+type HARContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Size     int    `json:"size"`
+}
+
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HAROptions HAR 录制配置
+type HAROptions struct {
+	// File 停止录制时写入的文件，为空则不写入
+	File string
+	// BodyMIME 需要记录响应体的 MIME 类型（前缀匹配），为空则不限制
+	BodyMIME []string
+	// MaxBodySize 响应体最大字节数，超出则不记录，0 为不限制
+	MaxBodySize int
+	// Body 是否记录响应体
+	Body bool
+}
+
+type harRecorder struct {
+	page     *rod.Page
+	cancel   context.CancelFunc
+	done     chan struct{}
+	requests map[proto.NetworkRequestID]*harRequest
+	options  HAROptions
+	started  time.Time
+	entries  []*harRequest
+	timings  HARPageTimings
+	base     proto.MonotonicTime
+	mu       sync.Mutex
+}
+
+type harRequest struct {
+	timing    *proto.NetworkResourceTiming
+	entry     HAREntry
+	timestamp proto.MonotonicTime
+	size      int
+	finished  bool
+}
+
+// StartHAR 开始录制页面的网络请求
+func (page *Page) StartHAR(opts ...func(o *HAROptions)) error {
+	if page.state == nil {
+		return errPageState
+	}
+
+	page.state.mu.Lock()
+	defer page.state.mu.Unlock()
+	if page.state.har != nil {
+		return errors.New("har recording has already started")
+	}
+
+	base := page.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithCancel(base)
+
+	r := &harRecorder{
+		page:     page.page.Context(base),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		requests: make(map[proto.NetworkRequestID]*harRequest),
+		options:  zutil.Optional(HAROptions{}, opts...),
+		started:  time.Now(),
+		timings:  HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+	}
+	wait := page.page.Context(ctx).EachEvent(
+		r.requestWillBeSent,
+		r.requestWillBeSentExtraInfo,
+		r.responseReceived,
+		r.dataReceived,
+		r.loadingFinished,
+		r.loadingFailed,
+		r.domContentEventFired,
+		r.loadEventFired,
+	)
+	go func() {
+		defer close(r.done)
+		wait()
+	}()
+
+	page.state.har = r
+	return nil
+}
+
+// StopHAR 停止录制，如果配置了 File 则写入文件
+func (page *Page) StopHAR() (*HAR, error) {
+	if page.state == nil {
+		return nil, errPageState
+	}
+
+	page.state.mu.Lock()
+	r := page.state.har
+	page.state.har = nil
+	page.state.mu.Unlock()
+	if r == nil {
+		return nil, errHARNotStarted
+	}
+
+	r.cancel()
+	<-r.done
+
+	title := ""
+	if info, err := r.page.Info(); err == nil {
+		title = info.Title
+		if title == "" {
+			title = info.URL
+		}
+	}
+
+	har := r.har(title)
+	if v, err := page.browser.Browser.Version(); err == nil {
+		product := strings.SplitN(v.Product, "/", 2)
+		har.Log.Browser = &HARCreator{Name: product[0]}
+		if len(product) > 1 {
+			har.Log.Browser.Version = product[1]
+		}
+	}
+
+	if r.options.File == "" {
+		return har, nil
+	}
+
+	b, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return har, err
+	}
+
+	return har, zfile.WriteFile(zfile.RealPath(r.options.File), b)
+}
+
+func (r *harRecorder) har(title string) *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]HAREntry, 0, len(r.entries))
+	for _, v := range r.entries {
+		entries = append(entries, v.entry)
+	}
+
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "github.com/zlsgo/browser"},
+		Pages: []HARPage{{
+			StartedDateTime: r.started,
+			ID:              "page_1",
+			Title:           title,
+			PageTimings:     r.timings,
+		}},
+		Entries: entries,
+	}}
+}
+
+func (r *harRecorder) requestWillBeSent(e *proto.NetworkRequestWillBeSent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.base == 0 {
+		r.base = e.Timestamp
+	}
+
+	if prev, ok := r.requests[e.RequestID]; ok && e.RedirectResponse != nil {
+		prev.response(e.RedirectResponse)
+		prev.entry.Response.RedirectURL = e.Request.URL
+		prev.finish(e.Timestamp, 0)
+	}
+
+	req := &harRequest{timestamp: e.Timestamp}
+	req.entry = HAREntry{
+		StartedDateTime: e.WallTime.Time(),
+		Pageref:         "page_1",
+		Request:         harRequestFromProto(e.Request),
+		Response: HARResponse{
+			Cookies: []HARCookie{},
+			Headers: []HARNameValue{},
+		},
+		Timings: HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+	}
+	r.requests[e.RequestID] = req
+	r.entries = append(r.entries, req)
+}
+
+func (r *harRecorder) requestWillBeSentExtraInfo(e *proto.NetworkRequestWillBeSentExtraInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req, ok := r.requests[e.RequestID]
+	if !ok || len(e.Headers) == 0 {
+		return
+	}
+
+	req.entry.Request.Headers = harHeaders(e.Headers)
+	req.entry.Request.Cookies = harRequestCookies(e.Headers)
+}
+
+func (r *harRecorder) responseReceived(e *proto.NetworkResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req, ok := r.requests[e.RequestID]; ok {
+		req.response(e.Response)
+	}
+}
+
+func (r *harRecorder) dataReceived(e *proto.NetworkDataReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req, ok := r.requests[e.RequestID]; ok {
+		req.size += e.DataLength
+	}
+}
+
+func (r *harRecorder) loadingFinished(e *proto.NetworkLoadingFinished) {
+	r.mu.Lock()
+	req, ok := r.requests[e.RequestID]
+	if !ok || req.finished {
+		r.mu.Unlock()
+		return
+	}
+	req.finish(e.Timestamp, e.EncodedDataLength)
+	fetch := r.options.Body && req.bodyAllowed(r.options)
+	r.mu.Unlock()
+
+	if !fetch {
+		return
+	}
+
+	body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(r.page)
+	if err != nil {
+		return
+	}
+
+	size := len(body.Body)
+	if body.Base64Encoded {
+		size = size * 3 / 4
+	}
+	if r.options.MaxBodySize > 0 && size > r.options.MaxBodySize {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req.entry.Response.Content.Text = body.Body
+	if body.Base64Encoded {
+		req.entry.Response.Content.Encoding = "base64"
+	}
+}
+
+func (r *harRecorder) loadingFailed(e *proto.NetworkLoadingFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req, ok := r.requests[e.RequestID]; ok && !req.finished {
+		req.entry.Comment = e.ErrorText
+		req.finish(e.Timestamp, 0)
+	}
+}
+
+func (r *harRecorder) domContentEventFired(e *proto.PageDomContentEventFired) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.base != 0 {
+		r.timings.OnContentLoad = float64(e.Timestamp-r.base) * 1000
+	}
+}
+
+func (r *harRecorder) loadEventFired(e *proto.PageLoadEventFired) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.base != 0 {
+		r.timings.OnLoad = float64(e.Timestamp-r.base) * 1000
+	}
+}
+
+func (req *harRequest) response(res *proto.NetworkResponse) {
+	req.timing = res.Timing
+	req.entry.Response = HARResponse{
+		Status:      res.Status,
+		StatusText:  res.StatusText,
+		HTTPVersion: harHTTPVersion(res.Protocol),
+		Cookies:     harResponseCookies(res.Headers),
+		Headers:     harHeaders(res.Headers),
+		Content:     HARContent{MimeType: res.MIMEType},
+		RedirectURL: "",
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if location, ok := harHeaderValue(res.Headers, "Location"); ok {
+		req.entry.Response.RedirectURL = location
+	}
+	req.entry.Request.HTTPVersion = req.entry.Response.HTTPVersion
+	if len(res.RequestHeaders) > 0 {
+		req.entry.Request.Headers = harHeaders(res.RequestHeaders)
+		req.entry.Request.Cookies = harRequestCookies(res.RequestHeaders)
+	}
+	req.entry.ServerIPAddress = res.RemoteIPAddress
+	if res.ConnectionID > 0 {
+		req.entry.Connection = strconv.FormatFloat(res.ConnectionID, 'f', -1, 64)
+	}
+}
+
+func (req *harRequest) finish(end proto.MonotonicTime, encodedLength float64) {
+	req.finished = true
+	req.entry.Response.Content.Size = req.size
+	if encodedLength > 0 {
+		req.entry.Response.BodySize = int(encodedLength)
+	}
+
+	req.entry.Timings = harTimings(req.timing, req.timestamp, end)
+	req.entry.Time = 0
+	for _, v := range []float64{
+		req.entry.Timings.Blocked,
+		req.entry.Timings.DNS,
+		req.entry.Timings.Connect,
+		req.entry.Timings.Send,
+		req.entry.Timings.Wait,
+		req.entry.Timings.Receive,
+	} {
+		if v > 0 {
+			req.entry.Time += v
+		}
+	}
+}
+
+func (req *harRequest) bodyAllowed(o HAROptions) bool {
+	if o.MaxBodySize > 0 && req.size > o.MaxBodySize {
+		return false
+	}
+
+	if len(o.BodyMIME) == 0 {
+		return true
+	}
+
+	mime := strings.ToLower(req.entry.Response.Content.MimeType)
+	for _, v := range o.BodyMIME {
+		if strings.HasPrefix(mime, strings.ToLower(v)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// harTimings 将 CDP 的资源时序转换为 HAR 时序，单位毫秒
+func harTimings(t *proto.NetworkResourceTiming, start, end proto.MonotonicTime) HARTimings {
+	timings := HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if t == nil {
+		if end > start {
+			timings.Wait = float64(end-start) * 1000
+		}
+		return timings
+	}
+
+	timings.Blocked = 0
+	for _, v := range []float64{t.DNSStart, t.ConnectStart, t.SendStart} {
+		if v >= 0 {
+			timings.Blocked = v
+			break
+		}
+	}
+	if t.DNSStart >= 0 {
+		timings.DNS = t.DNSEnd - t.DNSStart
+	}
+	if t.ConnectStart >= 0 {
+		timings.Connect = t.ConnectEnd - t.ConnectStart
+	}
+	if t.SslStart >= 0 {
+		timings.SSL = t.SslEnd - t.SslStart
+	}
+	timings.Send = t.SendEnd - t.SendStart
+	timings.Wait = t.ReceiveHeadersEnd - t.SendEnd
+	if end > 0 {
+		timings.Receive = (float64(end)-t.RequestTime)*1000 - t.ReceiveHeadersEnd
+	}
+	if timings.Receive < 0 {
+		timings.Receive = 0
+	}
+
+	return timings
+}
+
+func harRequestFromProto(r *proto.NetworkRequest) HARRequest {
+	u := r.URL + r.URLFragment
+	req := HARRequest{
+		Method:      r.Method,
+		URL:         u,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     harRequestCookies(r.Headers),
+		Headers:     harHeaders(r.Headers),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+
+	if parsed, err := url.Parse(u); err == nil {
+		for k, vs := range parsed.Query() {
+			for _, v := range vs {
+				req.QueryString = append(req.QueryString, HARNameValue{Name: k, Value: v})
+			}
+		}
+	}
+
+	if r.HasPostData || r.PostData != "" {
+		mime, _ := harHeaderValue(r.Headers, "Content-Type")
+		req.PostData = &HARPostData{MimeType: mime, Text: r.PostData}
+		req.BodySize = len(r.PostData)
+		if strings.HasPrefix(mime, "application/x-www-form-urlencoded") {
+			if values, err := url.ParseQuery(r.PostData); err == nil {
+				for k, vs := range values {
+					for _, v := range vs {
+						req.PostData.Params = append(req.PostData.Params, HARNameValue{Name: k, Value: v})
+					}
+				}
+			}
+		}
+	}
+
+	return req
+}
+
+func harHeaders(h proto.NetworkHeaders) []HARNameValue {
+	headers := make([]HARNameValue, 0, len(h))
+	for k, v := range h {
+		for _, s := range strings.Split(v.String(), "\n") {
+			headers = append(headers, HARNameValue{Name: k, Value: s})
+		}
+	}
+	return headers
+}
+
+func harHeaderValue(h proto.NetworkHeaders, name string) (string, bool) {
+	for k, v := range h {
+		if strings.EqualFold(k, name) {
+			return v.String(), true
+		}
+	}
+	return "", false
+}
+
+func harHTTPHeader(h proto.NetworkHeaders) http.Header {
+	header := http.Header{}
+	for _, v := range harHeaders(h) {
+		header.Add(v.Name, v.Value)
+	}
+	return header
+}
+
+func harRequestCookies(h proto.NetworkHeaders) []HARCookie {
+	cookies := []HARCookie{}
+	for _, c := range (&http.Request{Header: harHTTPHeader(h)}).Cookies() {
+		cookies = append(cookies, HARCookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
+
+func harResponseCookies(h proto.NetworkHeaders) []HARCookie {
+	cookies := []HARCookie{}
+	for _, c := range (&http.Response{Header: harHTTPHeader(h)}).Cookies() {
+		cookie := HARCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			expires := c.Expires
+			cookie.Expires = &expires
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return "HTTP/1.1"
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3.0"
+	default:
+		return strings.ToUpper(protocol)
+	}
+}
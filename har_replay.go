@@ -0,0 +1,277 @@
+package browser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zfile"
+)
+
+// HARReplayMode 回放未命中时的处理方式
+type HARReplayMode int
+
+const (
+	// HARReplayAbort 未命中的请求直接中止
+	HARReplayAbort HARReplayMode = iota
+	// HARReplayPassthrough 未命中的请求正常发出
+	HARReplayPassthrough
+	// HARReplayRecord 未命中的请求正常发出，并追加到 HAR 文件
+	HARReplayRecord
+)
+
+type harReplayOptions struct {
+	file      string
+	mode      HARReplayMode
+	matchBody bool
+}
+
+// ReplayHAR 使用 HAR 文件中的记录响应页面请求，按请求方法、URL 以及可选的请求体匹配
+func (o *PageOptions) ReplayHAR(file string, mode HARReplayMode, matchBody ...bool) {
+	o.replayHAR = &harReplayOptions{
+		file:      file,
+		mode:      mode,
+		matchBody: len(matchBody) > 0 && matchBody[0],
+	}
+}
+
+// LoadHAR 读取 HAR 文件
+func LoadHAR(file string) (*HAR, error) {
+	b, err := zfile.ReadFile(zfile.RealPath(file))
+	if err != nil {
+		return nil, err
+	}
+
+	har := &HAR{}
+	if err = json.Unmarshal(b, har); err != nil {
+		return nil, err
+	}
+
+	return har, nil
+}
+
+type harReplay struct {
+	har      *HAR
+	client   *http.Client
+	entries  map[string][]int
+	served   map[string]int
+	file     string
+	mode     HARReplayMode
+	recorded bool
+	mu       sync.Mutex
+	body     bool
+}
+
+func newHARReplay(o *harReplayOptions, client *http.Client) (*harReplay, error) {
+	var (
+		har *HAR
+		err error
+	)
+	if o.mode == HARReplayRecord && !zfile.FileExist(zfile.RealPath(o.file)) {
+		har = &HAR{Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "github.com/zlsgo/browser"},
+			Pages:   []HARPage{},
+			Entries: []HAREntry{},
+		}}
+	} else if har, err = LoadHAR(o.file); err != nil {
+		return nil, err
+	}
+
+	r := &harReplay{
+		har:     har,
+		client:  client,
+		entries: make(map[string][]int),
+		served:  make(map[string]int),
+		file:    o.file,
+		mode:    o.mode,
+		body:    o.matchBody,
+	}
+	for i := range har.Log.Entries {
+		req := har.Log.Entries[i].Request
+		key := r.key(req.Method, req.URL, harPostDataText(req.PostData))
+		r.entries[key] = append(r.entries[key], i)
+	}
+
+	return r, nil
+}
+
+func (r *harReplay) key(method, u, body string) string {
+	if i := strings.IndexByte(u, '#'); i >= 0 {
+		u = u[:i]
+	}
+
+	key := strings.ToUpper(method) + " " + u
+	if r.body {
+		key += "\n" + body
+	}
+	return key
+}
+
+// match 查找匹配的记录，同一请求有多条记录时按顺序依次返回，最后一条会被重复使用
+func (r *harReplay) match(method, u, body string) *HAREntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := r.key(method, u, body)
+	list := r.entries[key]
+	if len(list) == 0 {
+		return nil
+	}
+
+	i := r.served[key]
+	if i >= len(list) {
+		i = len(list) - 1
+	}
+	r.served[key] = i + 1
+
+	entry := r.har.Log.Entries[list[i]]
+	return &entry
+}
+
+func (r *harReplay) handle(h *Hijack) {
+	if h.CustomState != nil {
+		return
+	}
+
+	req := h.Hijack.Request
+	if entry := r.match(req.Method(), req.URL().String(), req.Body()); entry != nil {
+		h.CustomState = true
+		if entry.Response.Status == 0 {
+			h.Hijack.Response.Fail(proto.NetworkErrorReasonFailed)
+			return
+		}
+		r.fulfill(h.Hijack, entry)
+		return
+	}
+
+	switch r.mode {
+	case HARReplayPassthrough:
+		h.Skip = true
+	case HARReplayRecord:
+		h.CustomState = true
+		start := time.Now()
+		if err := h.Hijack.LoadResponse(r.client, true); err != nil {
+			h.Hijack.Response.Fail(proto.NetworkErrorReasonFailed)
+			return
+		}
+		r.record(h.Hijack, start)
+	default:
+		h.CustomState = true
+		h.Hijack.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+	}
+}
+
+func (r *harReplay) fulfill(h *rod.Hijack, entry *HAREntry) {
+	payload := h.Response.Payload()
+	payload.ResponseCode = entry.Response.Status
+	if entry.Response.StatusText != "" {
+		payload.ResponsePhrase = entry.Response.StatusText
+	}
+
+	for _, v := range entry.Response.Headers {
+		switch strings.ToLower(v.Name) {
+		case "content-encoding", "content-length", "transfer-encoding":
+			continue
+		}
+		h.Response.SetHeader(v.Name, v.Value)
+	}
+
+	content := entry.Response.Content
+	if content.Encoding == "base64" {
+		body, err := base64.StdEncoding.DecodeString(content.Text)
+		if err == nil {
+			h.Response.SetBody(body)
+			return
+		}
+	}
+	h.Response.SetBody(content.Text)
+}
+
+func (r *harReplay) record(h *rod.Hijack, start time.Time) {
+	req := h.Request
+	payload := h.Response.Payload()
+	headers := make(proto.NetworkHeaders)
+	for k, v := range req.Headers() {
+		headers[k] = v
+	}
+
+	entry := HAREntry{
+		StartedDateTime: start,
+		Request: harRequestFromProto(&proto.NetworkRequest{
+			URL:         req.URL().String(),
+			Method:      req.Method(),
+			Headers:     headers,
+			HasPostData: req.Body() != "",
+			PostData:    req.Body(),
+		}),
+		Response: HARResponse{
+			Status:      payload.ResponseCode,
+			StatusText:  http.StatusText(payload.ResponseCode),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARCookie{},
+			Headers:     make([]HARNameValue, 0, len(payload.ResponseHeaders)),
+			HeadersSize: -1,
+			BodySize:    len(payload.Body),
+		},
+		Timings: HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+		Time:    float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	entry.Timings.Wait = entry.Time
+
+	header := http.Header{}
+	for _, v := range payload.ResponseHeaders {
+		entry.Response.Headers = append(entry.Response.Headers, HARNameValue{Name: v.Name, Value: v.Value})
+		header.Add(v.Name, v.Value)
+	}
+	for _, c := range (&http.Response{Header: header}).Cookies() {
+		entry.Response.Cookies = append(entry.Response.Cookies, HARCookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain})
+	}
+	entry.Response.RedirectURL = header.Get("Location")
+	entry.Response.Content = HARContent{MimeType: header.Get("Content-Type"), Size: len(payload.Body)}
+	if utf8.Valid(payload.Body) {
+		entry.Response.Content.Text = string(payload.Body)
+	} else {
+		entry.Response.Content.Text = base64.StdEncoding.EncodeToString(payload.Body)
+		entry.Response.Content.Encoding = "base64"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := r.key(entry.Request.Method, entry.Request.URL, harPostDataText(entry.Request.PostData))
+	r.har.Log.Entries = append(r.har.Log.Entries, entry)
+	r.entries[key] = append(r.entries[key], len(r.har.Log.Entries)-1)
+	r.served[key] = len(r.entries[key])
+	r.recorded = true
+}
+
+// save 将录制模式下新增的记录写回 HAR 文件
+func (r *harReplay) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.recorded {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(r.har, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	r.recorded = false
+	return zfile.WriteFile(zfile.RealPath(r.file), b)
+}
+
+func harPostDataText(p *HARPostData) string {
+	if p == nil {
+		return ""
+	}
+	return p.Text
+}
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo"
+	"github.com/ysmood/gson"
+)
+
+func Test_harTimings(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	timings := harTimings(&proto.NetworkResourceTiming{
+		RequestTime:       10,
+		DNSStart:          1,
+		DNSEnd:            3,
+		ConnectStart:      3,
+		ConnectEnd:        8,
+		SslStart:          5,
+		SslEnd:            8,
+		SendStart:         8,
+		SendEnd:           9,
+		ReceiveHeadersEnd: 20,
+	}, 10, 10.05)
+	tt.Equal(float64(1), timings.Blocked)
+	tt.Equal(float64(2), timings.DNS)
+	tt.Equal(float64(5), timings.Connect)
+	tt.Equal(float64(3), timings.SSL)
+	tt.Equal(float64(1), timings.Send)
+	tt.Equal(float64(11), timings.Wait)
+	tt.Equal(true, timings.Receive > 29 && timings.Receive < 31)
+
+	timings = harTimings(&proto.NetworkResourceTiming{
+		DNSStart:     -1,
+		DNSEnd:       -1,
+		ConnectStart: -1,
+		ConnectEnd:   -1,
+		SslStart:     -1,
+		SslEnd:       -1,
+		SendStart:    2,
+		SendEnd:      3,
+	}, 0, 0)
+	tt.Equal(float64(2), timings.Blocked)
+	tt.Equal(float64(-1), timings.DNS)
+	tt.Equal(float64(-1), timings.Connect)
+	tt.Equal(float64(-1), timings.SSL)
+}
+
+func Test_harRequestFromProto(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	req := harRequestFromProto(&proto.NetworkRequest{
+		URL:         "https://example.com/search?q=go",
+		URLFragment: "#top",
+		Method:      "POST",
+		Headers: proto.NetworkHeaders{
+			"Content-Type": gson.New("application/x-www-form-urlencoded"),
+			"Cookie":       gson.New("a=1; b=2"),
+		},
+		HasPostData: true,
+		PostData:    "name=zlsgo",
+	})
+
+	tt.Equal("https://example.com/search?q=go#top", req.URL)
+	tt.Equal([]HARNameValue{{Name: "q", Value: "go"}}, req.QueryString)
+	tt.Equal(2, len(req.Cookies))
+	tt.Equal("name=zlsgo", req.PostData.Text)
+	tt.Equal([]HARNameValue{{Name: "name", Value: "zlsgo"}}, req.PostData.Params)
+}
+
+func Test_harReplayMatch(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	har := &HAR{Log: HARLog{Entries: []HAREntry{
+		{Request: HARRequest{Method: "GET", URL: "https://example.com/api"}, Response: HARResponse{Status: 200}},
+		{Request: HARRequest{Method: "GET", URL: "https://example.com/api#hash"}, Response: HARResponse{Status: 201}},
+		{Request: HARRequest{Method: "POST", URL: "https://example.com/api", PostData: &HARPostData{Text: "a=1"}}, Response: HARResponse{Status: 202}},
+	}}}
+
+	r := &harReplay{har: har, entries: map[string][]int{}, served: map[string]int{}, body: true}
+	for i := range har.Log.Entries {
+		req := har.Log.Entries[i].Request
+		key := r.key(req.Method, req.URL, harPostDataText(req.PostData))
+		r.entries[key] = append(r.entries[key], i)
+	}
+
+	tt.Equal(200, r.match("get", "https://example.com/api", "").Response.Status)
+	tt.Equal(201, r.match("GET", "https://example.com/api", "").Response.Status)
+	tt.Equal(201, r.match("GET", "https://example.com/api", "").Response.Status)
+	tt.Equal(202, r.match("POST", "https://example.com/api", "a=1").Response.Status)
+	tt.Equal(true, r.match("POST", "https://example.com/api", "a=2") == nil)
+	tt.Equal(true, r.match("GET", "https://example.com/other", "") == nil)
+}
+
+func Test_hijaclProcessHandled(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	called := false
+	h := &Hijack{Hijack: &rod.Hijack{CustomState: true}}
+	hijaclProcess(h, func(h *Hijack) bool {
+		called = true
+		return false
+	})
+	tt.EqualFalse(called)
+	tt.EqualTrue(h.Skip)
+}
@@ -0,0 +1,159 @@
+package browser
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// ErrStopHarvest 在 ScrollHarvest 的处理函数中返回该错误可提前结束采集，当前元素不计入采集数量
+var ErrStopHarvest = errors.New("stop harvest")
+
+// HarvestOptions 滚动采集选项
+type HarvestOptions struct {
+	// Key 元素去重键，默认使用元素文本，文本为空时使用 HTML 的 MD5，
+	// 虚拟列表会复用 DOM 节点，建议使用数据 ID 等稳定的值
+	Key func(e *Element) (string, error)
+	// MaxItems 最多采集数量，0 为不限制
+	MaxItems int
+	// IdleRounds 连续多少轮没有新元素时停止，默认 3
+	IdleRounds int
+	// Deadline 最长采集时间，默认为页面超时时间
+	Deadline time.Duration
+	// ScrollDuration 每轮滚动的预期耗时，默认 800ms
+	ScrollDuration time.Duration
+	// Wait 每轮滚动后等待新内容加载的时间，默认 1s
+	Wait time.Duration
+}
+
+// ScrollHarvest 模拟真人滚动页面并采集懒加载的元素，每个元素只会交给 fn 处理一次，返回采集数量
+func (page *Page) ScrollHarvest(itemSelector string, fn func(e *Element) error, opts ...func(o *HarvestOptions)) (int, error) {
+	o := zutil.Optional(HarvestOptions{
+		IdleRounds:     3,
+		ScrollDuration: 800 * time.Millisecond,
+		Wait:           time.Second,
+	}, opts...)
+	if o.Key == nil {
+		o.Key = harvestKey
+	}
+	if o.Deadline == 0 {
+		o.Deadline = page.GetTimeout()
+	}
+
+	var (
+		seen     = make(map[string]struct{})
+		count    int
+		idle     int
+		deadline = time.Now().Add(o.Deadline)
+	)
+	for {
+		es, err := queryElements(page.page, nil, itemSelector)
+		if err != nil {
+			return count, err
+		}
+
+		found := 0
+		for i := range es {
+			e := &Element{element: es[i], page: page}
+			key, err := o.Key(e)
+			if err != nil {
+				continue
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			found++
+
+			if err = fn(e); err != nil {
+				if errors.Is(err, ErrStopHarvest) {
+					return count, nil
+				}
+				return count, err
+			}
+			count++
+
+			if o.MaxItems > 0 && count >= o.MaxItems {
+				return count, nil
+			}
+		}
+
+		if found == 0 {
+			idle++
+			if idle >= o.IdleRounds {
+				return count, nil
+			}
+		} else {
+			idle = 0
+		}
+
+		if o.Deadline > 0 && time.Now().After(deadline) {
+			return count, nil
+		}
+
+		var last *Element
+		if len(es) > 0 {
+			last = &Element{element: es[len(es)-1], page: page}
+		}
+		if err = page.harvestScroll(last, o.ScrollDuration); err != nil {
+			return count, err
+		}
+
+		wait := int(o.Wait / time.Millisecond)
+		RandomSleep(wait, wait/2+1)
+	}
+}
+
+// harvestScroll 先自然滚动到最后一个元素，再在其上方滚动鼠标滚轮，兼容内部滚动容器
+func (page *Page) harvestScroll(last *Element, duration time.Duration) error {
+	viewport, err := page.EvalJS(`() => ({ width: window.innerWidth, height: window.innerHeight })`)
+	if err != nil {
+		return err
+	}
+
+	x, y := viewport.Get("width").Num()/2, viewport.Get("height").Num()/2
+	if last != nil {
+		_ = page.NaturalScroll(last, duration)
+		if box, err := last.Box(); err == nil && box.Width > 0 && box.Height > 0 {
+			x, y = box.X+box.Width/2, box.Y+box.Height/2
+		}
+	}
+
+	mouse := page.page.Mouse
+	if err = mouse.MoveTo(proto.Point{X: x, Y: y}); err != nil {
+		return err
+	}
+
+	distance := viewport.Get("height").Num() * (0.6 + rand.Float64()*0.3)
+	steps := 4 + rand.Intn(4)
+	for i := 0; i < steps; i++ {
+		if err = mouse.Scroll(0, distance/float64(steps), 1); err != nil {
+			return err
+		}
+		RandomSleep(30, 60)
+	}
+
+	return nil
+}
+
+func harvestKey(e *Element) (string, error) {
+	text, err := e.Text()
+	if err != nil {
+		return "", err
+	}
+
+	if text = strings.TrimSpace(text); text != "" {
+		return text, nil
+	}
+
+	html, err := e.HTML()
+	if err != nil {
+		return "", err
+	}
+	return zstring.Md5(html), nil
+}
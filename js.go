@@ -19,3 +19,142 @@ func (page *Page) EvalJS(js string, params ...interface{}) (gson.JSON, error) {
 	}
 	return resp.Value, nil
 }
+
+var jsSnapshot = `(...closed) => {
+	const resources = [], index = new Map(), frames = [], frameSrc = [];
+	let depth = 0;
+	const hosts = new Map(closed.filter(Boolean).map(r => [r.host, r]));
+	const shadow = el => el.shadowRoot || hosts.get(el) || null;
+	const voids = new Set(["area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr"]);
+	const escText = s => s.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+	const escAttr = s => s.replace(/&/g, "&amp;").replace(/"/g, "&quot;");
+	const absolute = (doc, url) => {
+		try { return new URL(url, doc.baseURI).href } catch (e) { return url }
+	};
+	const resource = (doc, url, kind) => {
+		url = (url || "").trim();
+		if (!url) return url;
+		const abs = absolute(doc, url);
+		if (!/^https?:/i.test(abs)) return abs;
+		if (!index.has(abs)) {
+			index.set(abs, resources.length);
+			resources.push({ url: abs, kind: kind || "" });
+		}
+		return "__zls_resource_" + index.get(abs) + "_" + depth + "__";
+	};
+	const cssURL = (doc, css) => css
+		.replace(/@import\s+(['"])([^'"]+)\1/g, (m, q, u) => "@import " + q + resource(doc, u, "css") + q)
+		.replace(/(@import\s+)?url\(\s*(['"]?)([^'")]+)\2\s*\)/g, (m, imp, q, u) =>
+			(imp || "") + "url(" + q + resource(doc, u, imp ? "css" : "") + q + ")");
+	const srcset = (doc, v) => v.split(",").map(s => {
+		const p = s.trim().split(/\s+/);
+		p[0] = resource(doc, p[0]);
+		return p.join(" ");
+	}).join(", ");
+
+	const attrs = (doc, el) => {
+		const tag = el.localName, out = [];
+		for (const a of el.attributes) {
+			let name = a.name, v = a.value;
+			if (tag === "iframe" || tag === "frame") {
+				if (name === "src" || name === "srcdoc") continue;
+			} else if (name === "src" || name === "poster" || (name === "href" && tag === "link") || (name === "data" && tag === "object")) {
+				const rel = tag === "link" ? (el.rel || "").toLowerCase() : "";
+				v = resource(doc, v, rel.includes("stylesheet") ? "css" : "");
+			} else if (name === "srcset") {
+				v = srcset(doc, v);
+			} else if (name === "href" || name === "action") {
+				v = absolute(doc, v);
+			} else if (name === "style") {
+				v = cssURL(doc, v);
+			} else if ((tag === "input" || tag === "option") && (name === "value" || name === "checked" || name === "selected")) {
+				continue;
+			}
+			out.push(" " + name + '="' + escAttr(v) + '"');
+		}
+		if (tag === "input") {
+			if (el.type === "checkbox" || el.type === "radio") {
+				if (el.checked) out.push(" checked");
+			} else if (el.type !== "file" && el.value !== "") {
+				out.push(' value="' + escAttr(el.value) + '"');
+			}
+		} else if (tag === "option") {
+			if (el.selected) out.push(" selected");
+			if (el.hasAttribute("value")) out.push(' value="' + escAttr(el.value) + '"');
+		} else if (tag === "iframe" || tag === "frame") {
+			let inner = null;
+			try { inner = el.contentDocument } catch (e) {}
+			if (inner && inner.documentElement) {
+				depth++;
+				const html = serializeDocument(inner);
+				depth--;
+				out.push(' srcdoc="' + escAttr(html) + '"');
+			} else if (el.getAttribute("src")) {
+				frames.push(el);
+				frameSrc.push(absolute(doc, el.getAttribute("src")));
+				out.push(" __zls_frame_" + (frames.length - 1) + "_" + depth + "__");
+			}
+		}
+		return out.join("");
+	};
+
+	const children = (doc, parent) => {
+		let html = "";
+		for (const child of parent.childNodes) html += node(doc, child);
+		return html;
+	};
+
+	const node = (doc, n) => {
+		if (n.nodeType === Node.TEXT_NODE) {
+			const parent = n.parentNode && n.parentNode.localName;
+			return parent === "style" ? cssURL(doc, n.data) : escText(n.data);
+		}
+		if (n.nodeType === Node.COMMENT_NODE) return "<!--" + n.data + "-->";
+		if (n.nodeType !== Node.ELEMENT_NODE) return "";
+
+		const tag = n.localName;
+		if (tag === "script" || tag === "base") return "";
+		if (tag === "meta") {
+			const equiv = (n.httpEquiv || "").toLowerCase();
+			if (n.hasAttribute("charset") || equiv === "content-type" || equiv === "content-security-policy") return "";
+		}
+		if (tag === "canvas") {
+			try {
+				return '<img src="' + n.toDataURL() + '" width="' + n.width + '" height="' + n.height + '">';
+			} catch (e) {}
+		}
+
+		let html = "<" + tag + attrs(doc, n) + ">";
+		if (voids.has(tag)) return html;
+		const root = shadow(n);
+		if (root) {
+			html += '<template shadowrootmode="' + root.mode + '">' + children(doc, root) + "</template>";
+		}
+		if (tag === "head") html += '<meta charset="utf-8">';
+		if (tag === "textarea") {
+			html += escText(n.value);
+		} else if (tag === "template") {
+			html += children(doc, n.content);
+		} else {
+			html += children(doc, n);
+		}
+		return html + "</" + tag + ">";
+	};
+
+	const serializeDocument = doc => {
+		let html = "";
+		if (doc.doctype) html += "<!DOCTYPE " + doc.doctype.name + ">";
+		return html + node(doc, doc.documentElement);
+	};
+
+	const html = serializeDocument(document);
+	return { html, resources, frames: frameSrc, owners: frames };
+}`
+
+var jsSnapshotResult = `function () {
+	return { html: this.html, resources: this.resources, frames: this.frames };
+}`
+
+var jsSnapshotOwners = `function () {
+	return this.owners;
+}`
@@ -0,0 +1,297 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/ysmood/gson"
+)
+
+// Locator 延迟定位器，只保存查询条件，每次调用时重新查找元素，不会因页面重新渲染而失效
+type Locator struct {
+	page     *Page
+	selector string
+	force    bool
+}
+
+// LocatorFilter 定位器过滤条件
+type LocatorFilter struct {
+	// Has 包含匹配该定位器的子元素
+	Has *Locator
+	// HasText 包含指定文本，忽略大小写
+	HasText string
+}
+
+// Locator 创建定位器
+func (page *Page) Locator(selector string) *Locator {
+	return &Locator{page: page, selector: selector}
+}
+
+// String 返回等价的选择器，可直接用于 Element、FluentPage 及 action 中的选择器参数
+func (l *Locator) String() string {
+	return l.selector
+}
+
+// On 在指定页面（例如框架页面）中使用相同查询条件的定位器
+func (l *Locator) On(page *Page) *Locator {
+	return &Locator{page: page, selector: l.selector, force: l.force}
+}
+
+func (l *Locator) chain(selector string) *Locator {
+	return &Locator{page: l.page, selector: l.selector + " " + ChainSeparator + " " + selector, force: l.force}
+}
+
+// Locator 在当前匹配的元素内查找
+func (l *Locator) Locator(selector string) *Locator {
+	return l.chain(selector)
+}
+
+// Nth 第 n 个匹配的元素，从 0 开始，负数表示倒数
+func (l *Locator) Nth(n int) *Locator {
+	return l.chain("nth=" + strconv.Itoa(n))
+}
+
+// First 第一个匹配的元素
+func (l *Locator) First() *Locator {
+	return l.Nth(0)
+}
+
+// Last 最后一个匹配的元素
+func (l *Locator) Last() *Locator {
+	return l.Nth(-1)
+}
+
+// Filter 过滤匹配的元素
+func (l *Locator) Filter(opts ...func(o *LocatorFilter)) *Locator {
+	o := zutil.Optional(LocatorFilter{}, opts...)
+
+	n := l
+	if o.HasText != "" {
+		text, _ := json.Marshal(o.HasText)
+		n = n.chain("has-text=" + string(text))
+	}
+	if o.Has != nil {
+		has, _ := json.Marshal(o.Has.selector)
+		n = n.chain("has=" + string(has))
+	}
+	return n
+}
+
+// Force 返回跳过可操作性检查的定位器
+func (l *Locator) Force() *Locator {
+	return &Locator{page: l.page, selector: l.selector, force: true}
+}
+
+// Element 查找第一个匹配的元素，会等待元素出现
+func (l *Locator) Element() (*Element, error) {
+	return l.element(l.page)
+}
+
+func (l *Locator) element(page *Page) (*Element, error) {
+	e, err := page.Element(l.selector)
+	if err != nil {
+		return nil, err
+	}
+	e.force = l.force
+	e.located = true
+	return e, nil
+}
+
+// Elements 查找所有匹配的元素，不会等待元素出现
+func (l *Locator) Elements() (Elements, error) {
+	es, err := queryElements(l.page.page, nil, l.selector)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make(Elements, 0, len(es))
+	for i := range es {
+		elements = append(elements, &Element{element: es[i], page: l.page, force: l.force})
+	}
+	return elements, nil
+}
+
+// Count 匹配的元素数量
+func (l *Locator) Count() (int, error) {
+	es, err := queryElements(l.page.page, nil, l.selector)
+	if err != nil {
+		return 0, err
+	}
+	return len(es), nil
+}
+
+// All 为每个匹配的元素创建定位器
+func (l *Locator) All() ([]*Locator, error) {
+	n, err := l.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Locator, 0, n)
+	for i := 0; i < n; i++ {
+		list = append(list, l.Nth(i))
+	}
+	return list, nil
+}
+
+// do 查找元素并执行操作，元素在操作前被移除时会重新查找，直到页面超时
+func (l *Locator) do(fn func(e *Element) error) error {
+	timeout := l.page.GetTimeout()
+	deadline := time.Now().Add(timeout)
+	for {
+		page := l.page
+		if timeout > 0 {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return context.DeadlineExceeded
+			}
+			page = page.Timeout(remaining)
+		}
+
+		e, err := l.element(page)
+		if err != nil {
+			return err
+		}
+
+		err = fn(e)
+		var actionErr *ActionabilityError
+		if err == nil || !errors.As(err, &actionErr) || actionErr.Check != CheckAttached {
+			return err
+		}
+	}
+}
+
+// Click 点击元素
+func (l *Locator) Click(button ...proto.InputMouseButton) error {
+	return l.do(func(e *Element) error {
+		return e.Click(button...)
+	})
+}
+
+// InputText 输入文字
+func (l *Locator) InputText(text string, clear ...bool) error {
+	return l.do(func(e *Element) error {
+		return e.InputText(text, clear...)
+	})
+}
+
+// InputEnter 输入回车
+func (l *Locator) InputEnter(presskeys ...input.Key) error {
+	return l.do(func(e *Element) error {
+		if err := e.Focus(); err != nil {
+			return err
+		}
+		return e.InputEnter(presskeys...)
+	})
+}
+
+// Focus 聚焦元素
+func (l *Locator) Focus() error {
+	return l.do(func(e *Element) error {
+		return e.Focus()
+	})
+}
+
+// Hover 鼠标悬停在元素上
+func (l *Locator) Hover() error {
+	return l.do(func(e *Element) error {
+		if err := e.WaitActionable(); err != nil {
+			return err
+		}
+		return e.element.Hover()
+	})
+}
+
+// WaitActionable 等待元素通过可操作性检查
+func (l *Locator) WaitActionable(checks ...ActionabilityCheck) error {
+	return l.do(func(e *Element) error {
+		return e.WaitActionable(checks...)
+	})
+}
+
+// Screenshot 截图元素
+func (l *Locator) Screenshot(file string, opts ...func(o *ScreenshotOptions)) error {
+	return l.do(func(e *Element) error {
+		return e.Screenshot(file, opts...)
+	})
+}
+
+// Text 获取元素文本
+func (l *Locator) Text() (text string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		text, err = e.Text()
+		return
+	})
+	return
+}
+
+// HTML 获取元素 HTML
+func (l *Locator) HTML() (html string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		html, err = e.HTML()
+		return
+	})
+	return
+}
+
+// TagName 获取元素标签名
+func (l *Locator) TagName() (name string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		name, err = e.TagName()
+		return
+	})
+	return
+}
+
+// Property 获取元素属性
+func (l *Locator) Property(name string) (value gson.JSON, err error) {
+	err = l.do(func(e *Element) (err error) {
+		value, err = e.Property(name)
+		return
+	})
+	return
+}
+
+// Box 获取元素位置及大小
+func (l *Locator) Box() (box *proto.DOMRect, err error) {
+	err = l.do(func(e *Element) (err error) {
+		box, err = e.Box()
+		return
+	})
+	return
+}
+
+// HasClassName 检查元素是否包含指定类名
+func (l *Locator) HasClassName(className string) bool {
+	e, err := l.Element()
+	if err != nil {
+		return false
+	}
+	return e.HasClassName(className)
+}
+
+// Visible 元素是否存在且可见，不会等待元素出现
+func (l *Locator) Visible() bool {
+	has, e := hasElement(l.page.page, nil, l.selector)
+	if !has {
+		return false
+	}
+	visible, _ := e.Visible()
+	return visible
+}
+
+// ROD 查找第一个匹配的元素并返回 rod 实例
+func (l *Locator) ROD() (*rod.Element, error) {
+	e, err := l.Element()
+	if err != nil {
+		return nil, err
+	}
+	return e.element, nil
+}
@@ -0,0 +1,80 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestLocatorReresolve(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><button id="save" disabled>save</button><script>
+setTimeout(() => {
+	const old = document.getElementById("save");
+	old.remove();
+	setTimeout(() => {
+		const btn = document.createElement("button");
+		btn.id = "save";
+		btn.textContent = "save";
+		btn.onclick = () => document.title = "clicked";
+		document.body.appendChild(btn);
+	}, 200);
+}, 500);
+</script></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		start := time.Now()
+		if err := p.Locator("#save").Click(); err != nil {
+			return err
+		}
+		tt.EqualTrue(time.Since(start) < 5*time.Second)
+
+		title, err := p.EvalJS(`() => document.title`)
+		if err != nil {
+			return err
+		}
+		tt.Equal("clicked", title.Str())
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+}
+
+func TestFluentLocator(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><input id="name"><button id="save" onclick="document.title=document.getElementById('name').value">save</button></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		name, save := p.Locator("#name"), p.Locator("#save")
+		if err := p.Chain().TypeIntoLocator(name, "zls").ClickLocator(save).Error(); err != nil {
+			return err
+		}
+
+		title, err := p.EvalJS(`() => document.title`)
+		if err != nil {
+			return err
+		}
+		tt.Equal("zls", title.Str())
+
+		tt.NoError(p.Chain().Locate(save).Error())
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+}
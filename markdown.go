@@ -0,0 +1,351 @@
+package browser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// MarkdownOptions 正文提取选项
+type MarkdownOptions struct {
+	// Selector 正文根元素选择器，设置后跳过正文识别
+	Selector string
+	// Full 为 true 时转换整个页面，只去掉导航、脚本等样板内容
+	Full bool
+	// Links 是否保留链接，默认 true，否则只保留链接文本
+	Links bool
+	// Images 是否保留图片，默认 true，否则只保留图片的 alt 文本
+	Images bool
+}
+
+// Markdown 页面正文及元数据
+type Markdown struct {
+	Title     string `json:"title"`
+	Byline    string `json:"byline"`
+	Lang      string `json:"lang"`
+	Excerpt   string `json:"excerpt"`
+	SiteName  string `json:"siteName"`
+	URL       string `json:"url"`
+	Content   string `json:"content"`
+	WordCount int    `json:"wordCount"`
+}
+
+var jsMarkdown = `(opts) => {
+	const junkTags = new Set(["SCRIPT", "STYLE", "NOSCRIPT", "TEMPLATE", "IFRAME", "OBJECT", "EMBED", "CANVAS", "SVG",
+		"FORM", "BUTTON", "INPUT", "SELECT", "TEXTAREA", "NAV", "ASIDE", "FOOTER", "DIALOG", "MENU", "LINK", "META"]);
+	const junkRoles = new Set(["navigation", "banner", "contentinfo", "complementary", "search", "dialog", "alertdialog", "menu", "menubar"]);
+	const unlikely = /comment|sidebar|footer|masthead|\bnav|menu|share|social|related|advert|\bads?\b|\bad-|sponsor|promo|popup|modal|cookie|consent|banner|breadcrumb|pagination|pager|subscribe|newsletter|signup|disqus|widget|outbrain|taboola|toolbar/i;
+	const positive = /article|content|\bmain|body|post|entry|story|text|blog|prose/i;
+	const negative = /hidden|\bhide|comment|footer|sidebar|sponsor|share|meta|promo|related|widget|\bad-|advert/i;
+	const blocks = new Set(["ADDRESS", "ARTICLE", "BLOCKQUOTE", "DETAILS", "DIV", "DL", "FIELDSET", "FIGCAPTION", "FIGURE",
+		"H1", "H2", "H3", "H4", "H5", "H6", "HEADER", "HR", "LI", "MAIN", "OL", "P", "PRE", "SECTION", "SUMMARY", "TABLE", "UL"]);
+
+	const absolute = (url) => {
+		try { return new URL(url, document.baseURI).href } catch (e) { return url }
+	};
+	const classID = (el) => (typeof el.className === "string" ? el.className : "") + " " + (el.id || "");
+	const hidden = (el) => {
+		if (el.hidden || el.getAttribute("aria-hidden") === "true") return true;
+		const style = getComputedStyle(el);
+		return style.display === "none" || style.visibility === "hidden";
+	};
+	const textOf = (el) => (el.innerText || el.textContent || "").replace(/\s+/g, " ").trim();
+	const linkDensity = (el) => {
+		const len = textOf(el).length;
+		if (!len) return 0;
+		let links = 0;
+		for (const a of el.querySelectorAll("a")) links += textOf(a).length;
+		return links / len;
+	};
+	const junk = (el, strict) => {
+		if (junkTags.has(el.tagName.toUpperCase())) return true;
+		if (junkRoles.has(el.getAttribute("role"))) return true;
+		if (el.tagName === "HEADER" && !el.closest("article, main, [role=main]")) return true;
+		if (hidden(el)) return true;
+		const c = classID(el);
+		if (!unlikely.test(c) || positive.test(c) || el.tagName === "BODY" || el.tagName === "ARTICLE" || el.tagName === "MAIN") return false;
+		return strict || textOf(el).length < 200 || linkDensity(el) > 0.3;
+	};
+
+	const detect = () => {
+		const scores = new Map();
+		const init = (el) => {
+			if (scores.has(el)) return;
+			let score = 0;
+			switch (el.tagName) {
+			case "ARTICLE": case "MAIN": score += 10; break;
+			case "DIV": case "SECTION": score += 5; break;
+			case "PRE": case "TD": case "BLOCKQUOTE": score += 3; break;
+			case "OL": case "UL": case "DL": case "FORM": case "TH": score -= 3; break;
+			case "H1": case "H2": case "H3": case "H4": case "H5": case "H6": score -= 5; break;
+			}
+			const c = classID(el);
+			if (positive.test(c)) score += 25;
+			if (negative.test(c)) score -= 25;
+			scores.set(el, score);
+		};
+
+		const skip = new Set();
+		for (const el of document.body.querySelectorAll("*")) {
+			if (skip.has(el.parentElement) || junk(el, true)) skip.add(el);
+		}
+
+		for (const p of document.body.querySelectorAll("p, pre, td, blockquote, li, div")) {
+			if (skip.has(p)) continue;
+			if (p.tagName === "DIV" && p.querySelector("p, div, pre, table, ul, ol, blockquote")) continue;
+			const text = textOf(p);
+			if (text.length < 25) continue;
+
+			const score = 1 + text.split(/[,，、]/).length + Math.min(Math.floor(text.length / 100), 3);
+			let ancestor = p.parentElement;
+			for (let level = 0; ancestor && ancestor !== document.documentElement && level < 3; level++) {
+				init(ancestor);
+				scores.set(ancestor, scores.get(ancestor) + score / (level === 0 ? 1 : level * 2));
+				ancestor = ancestor.parentElement;
+			}
+		}
+
+		let top = null, best = 0;
+		for (const [el, score] of scores) {
+			const s = score * (1 - linkDensity(el));
+			scores.set(el, s);
+			if (s > best) { best = s; top = el; }
+		}
+		if (!top) return [document.querySelector("article, main, [role=main]") || document.body];
+
+		const parent = top.parentElement;
+		if (!parent || top === document.body) return [top];
+		const threshold = Math.max(10, best * 0.2);
+		const nodes = [];
+		for (const sibling of parent.children) {
+			if (sibling === top) { nodes.push(sibling); continue; }
+			if (junk(sibling, true)) continue;
+			const text = textOf(sibling), density = linkDensity(sibling);
+			if ((scores.get(sibling) || 0) >= threshold ||
+				(sibling.tagName === "P" && ((text.length > 80 && density < 0.25) || (text.length > 0 && density === 0 && /[.。]$/.test(text))))) {
+				nodes.push(sibling);
+			}
+		}
+		return nodes;
+	};
+
+	const escape = (s) => s.replace(/([\\*\x60\[\]])/g, "\\$1");
+	const inlineCode = (s) => {
+		const ticks = (s.match(/\x60+/g) || []).reduce((n, t) => Math.max(n, t.length + 1), 1);
+		const fence = "\x60".repeat(ticks);
+		return fence + (ticks > 1 ? " " + s + " " : s) + fence;
+	};
+	const image = (img) => {
+		const alt = (img.getAttribute("alt") || "").replace(/\s+/g, " ").trim();
+		if (!opts.images) return escape(alt);
+		let src = img.currentSrc || img.getAttribute("src") || img.getAttribute("data-src") || "";
+		if (src.startsWith("data:")) src = "";
+		return "![" + escape(alt) + "](" + (src ? absolute(src) : "") + ")";
+	};
+
+	const inline = (node, pre) => {
+		let out = "";
+		for (const child of node.childNodes) out += convert(child, pre);
+		return out;
+	};
+	const emphasis = (el, mark) => {
+		const raw = inline(el), text = raw.trim();
+		if (!text) return raw;
+		return raw.slice(0, raw.indexOf(text)) + mark + text + mark + raw.slice(raw.indexOf(text) + text.length);
+	};
+	const block = (s) => "\n\n" + s.trim() + "\n\n";
+	const indent = (s, prefix) => s.split("\n").map((l, i) => (i === 0 || l === "" ? l : prefix + l)).join("\n");
+
+	const table = (el) => {
+		const rows = Array.from(el.rows).map((r) => {
+			const cells = [];
+			for (const c of r.cells) {
+				const text = inline(c).replace(/\s+/g, " ").trim().replace(/\|/g, "\\|");
+				for (let i = 0; i < Math.max(1, c.colSpan); i++) cells.push(i === 0 ? text : "");
+			}
+			return cells;
+		}).filter((r) => r.length);
+		if (!rows.length) return "";
+		const width = Math.max(...rows.map((r) => r.length));
+		const line = (r) => "| " + Array.from({ length: width }, (_, i) => r[i] || "").join(" | ") + " |";
+		const out = [line(rows[0]), "|" + " --- |".repeat(width)];
+		for (const r of rows.slice(1)) out.push(line(r));
+		return block(out.join("\n"));
+	};
+
+	const list = (el) => {
+		const ordered = el.tagName === "OL";
+		let n = parseInt(el.getAttribute("start"), 10) || 1;
+		const items = [];
+		for (const li of el.children) {
+			if (li.tagName !== "LI" || junk(li)) continue;
+			const marker = ordered ? (n++) + ". " : "- ";
+			const body = inline(li).replace(/\n{3,}/g, "\n\n").trim();
+			items.push(marker + indent(body, " ".repeat(marker.length)));
+		}
+		return block(items.join("\n"));
+	};
+
+	const convert = (node, pre) => {
+		if (node.nodeType === Node.TEXT_NODE) {
+			if (pre) return node.textContent;
+			return escape(node.textContent.replace(/\s+/g, " "));
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE) return "";
+
+		const el = node;
+		if (junk(el)) return "";
+		const tag = el.tagName.toUpperCase();
+		switch (tag) {
+		case "H1": case "H2": case "H3": case "H4": case "H5": case "H6": {
+			const text = inline(el).replace(/\s+/g, " ").trim();
+			return text ? block("#".repeat(+tag[1]) + " " + text) : "";
+		}
+		case "P": case "DIV": case "SECTION": case "ARTICLE": case "MAIN": case "HEADER":
+		case "FIGURE": case "FIGCAPTION": case "DETAILS": case "SUMMARY": case "ADDRESS": case "CENTER":
+			return block(inline(el));
+		case "BR":
+			return "  \n";
+		case "HR":
+			return block("---");
+		case "STRONG": case "B":
+			return emphasis(el, "**");
+		case "EM": case "I":
+			return emphasis(el, "*");
+		case "DEL": case "S":
+			return emphasis(el, "~~");
+		case "CODE":
+			if (pre) return el.textContent;
+			return inlineCode(el.textContent);
+		case "PRE": {
+			const code = el.querySelector("code");
+			const lang = ((code || el).className.match(/(?:lang|language)-([\w+#-]+)/) || [])[1] || "";
+			const text = (el.innerText || el.textContent).replace(/\n+$/, "");
+			const fence = text.includes("\x60\x60\x60") ? "~~~" : "\x60\x60\x60";
+			return "\n\n" + fence + lang + "\n" + text + "\n" + fence + "\n\n";
+		}
+		case "BLOCKQUOTE": {
+			const text = inline(el).replace(/\n{3,}/g, "\n\n").trim();
+			return text ? block(text.split("\n").map((l) => "> " + l).join("\n")) : "";
+		}
+		case "UL": case "OL":
+			return list(el);
+		case "LI":
+			return block("- " + inline(el).trim());
+		case "DL":
+			return block(inline(el));
+		case "DT":
+			return "\n**" + inline(el).trim() + "**\n";
+		case "DD":
+			return ": " + inline(el).trim() + "\n";
+		case "TABLE":
+			return table(el);
+		case "IMG":
+			return image(el);
+		case "PICTURE": {
+			const img = el.querySelector("img");
+			return img ? image(img) : "";
+		}
+		case "A": {
+			const text = inline(el).replace(/\s+/g, " ").trim();
+			const href = el.getAttribute("href") || "";
+			if (!text || !opts.links || !href || href.startsWith("#") || /^javascript:/i.test(href)) return text;
+			return "[" + text + "](" + absolute(href) + ")";
+		}
+		default:
+			if (blocks.has(tag)) return block(inline(el, pre));
+			return inline(el, pre);
+		}
+	};
+
+	const meta = (...names) => {
+		for (const name of names) {
+			const el = document.querySelector("meta[name='" + name + "'], meta[property='" + name + "'], meta[itemprop='" + name + "']");
+			const content = el && (el.getAttribute("content") || "").trim();
+			if (content) return content;
+		}
+		return "";
+	};
+
+	let nodes;
+	if (opts.selector) {
+		nodes = [document.querySelector(opts.selector)];
+		if (!nodes[0]) throw new Error("element not found: " + opts.selector);
+	} else {
+		nodes = opts.full ? [document.body] : detect();
+	}
+
+	let content = nodes.map((n) => convert(n)).join("\n\n");
+	content = content.replace(/[ \t]+\n/g, (m) => (m.startsWith("  ") ? "  \n" : "\n")).replace(/\n{3,}/g, "\n\n").trim();
+
+	const byline = meta("author", "article:author", "byl", "dc.creator") ||
+		textOf(document.querySelector("[rel=author], [itemprop=author], .byline, .author") || document.createElement("i"));
+	const h1 = nodes.map((n) => n.querySelector("h1")).find((h) => h);
+	return {
+		title: meta("og:title", "twitter:title") || (h1 ? textOf(h1) : "") || document.title.trim(),
+		byline: byline.slice(0, 120),
+		lang: document.documentElement.lang || meta("og:locale", "language", "content-language") ||
+			((document.querySelector("meta[http-equiv='content-language' i]") || {}).content || ""),
+		excerpt: meta("og:description", "description", "twitter:description"),
+		siteName: meta("og:site_name", "application-name"),
+		url: location.href,
+		content,
+	};
+}`
+
+var markdownSyntax = regexp.MustCompile("(?m)^#{1,6} |^> |^\\s*(?:[-*+]|\\d+\\.) |^\\|?(?: ?-{3} ?\\|)+$|!?\\[|\\]\\([^)]*\\)|[*_~`|]+")
+
+// Markdown 识别页面正文并转换为 Markdown，去掉导航、广告等样板内容，
+// 保留标题、列表、表格、链接（转为绝对地址）及图片的 alt 文本
+func (page *Page) Markdown(opts ...func(o *MarkdownOptions)) (*Markdown, error) {
+	o := zutil.Optional(MarkdownOptions{Links: true, Images: true}, opts...)
+
+	res, err := page.Timeout().page.Eval(jsMarkdown, map[string]interface{}{
+		"selector": o.Selector,
+		"full":     o.Full,
+		"links":    o.Links,
+		"images":   o.Images,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	md := &Markdown{}
+	if err = res.Value.Unmarshal(md); err != nil {
+		return nil, err
+	}
+	md.WordCount = wordCount(markdownSyntax.ReplaceAllString(md.Content, " "))
+
+	return md, nil
+}
+
+// String 返回带标题的 Markdown 文本
+func (m *Markdown) String() string {
+	if m.Title == "" || strings.HasPrefix(m.Content, "# ") {
+		return m.Content
+	}
+	return "# " + m.Title + "\n\n" + m.Content
+}
+
+// wordCount 统计字数，中日韩文字按字计数，其他文字按单词计数
+func wordCount(s string) int {
+	n, inWord := 0, false
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			n++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				n++
+				inWord = true
+			}
+		case r == '\'' || r == '-':
+		default:
+			inWord = false
+		}
+	}
+	return n
+}
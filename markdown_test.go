@@ -0,0 +1,21 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestMarkdownWordCount(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tt.Equal(0, wordCount(""))
+	tt.Equal(4, wordCount("Don't panic, read-only mode"))
+	tt.Equal(5, wordCount("Go 语言很好"))
+
+	text := markdownSyntax.ReplaceAllString("## Title\n\n- [Go](https://go.dev) **is** fun\n\n| a | b |\n| --- | --- |", " ")
+	tt.Equal(6, wordCount(text))
+
+	md := &Markdown{Title: "Hello", Content: "world"}
+	tt.Equal("# Hello\n\nworld", md.String())
+}
@@ -0,0 +1,592 @@
+package browser
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zarray"
+)
+
+// Metadata 页面结构化元数据
+type Metadata struct {
+	Title string `json:"title"`
+	// Description meta description
+	Description string `json:"description"`
+	// Canonical 规范地址
+	Canonical string   `json:"canonical"`
+	Lang      string   `json:"lang"`
+	Charset   string   `json:"charset"`
+	Keywords  []string `json:"keywords"`
+	// Robots robots 指令，例如 noindex、nofollow
+	Robots []string `json:"robots"`
+	// OpenGraph OpenGraph 协议
+	OpenGraph OpenGraph `json:"openGraph"`
+	// Twitter Twitter 卡片
+	Twitter TwitterCard `json:"twitter"`
+	// Alternates 多语言版本 hreflang
+	Alternates []MetaLink `json:"alternates"`
+	// Feeds RSS、Atom、JSON Feed 订阅
+	Feeds []MetaLink `json:"feeds"`
+	// Icons 网站图标
+	Icons []MetaLink `json:"icons"`
+	// JSONLD 解析后的 JSON-LD 数据
+	JSONLD []interface{} `json:"jsonld"`
+	// Microdata 微数据（itemscope）顶层条目
+	Microdata []MicrodataItem `json:"microdata"`
+	// RDFa RDFa Lite（typeof）顶层条目
+	RDFa []MicrodataItem `json:"rdfa"`
+}
+
+// MetaLink link 标签
+type MetaLink struct {
+	Href     string `json:"href"`
+	Rel      string `json:"rel,omitempty"`
+	Hreflang string `json:"hreflang,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Sizes    string `json:"sizes,omitempty"`
+}
+
+// OpenGraph OpenGraph 协议字段，Raw 包含所有 og:、article:、product: 等属性
+type OpenGraph struct {
+	Title       string              `json:"title"`
+	Type        string              `json:"type"`
+	URL         string              `json:"url"`
+	Description string              `json:"description"`
+	SiteName    string              `json:"siteName"`
+	Locale      string              `json:"locale"`
+	Images      []string            `json:"images"`
+	Videos      []string            `json:"videos"`
+	Raw         map[string][]string `json:"raw"`
+}
+
+// TwitterCard Twitter 卡片字段，Raw 包含所有 twitter: 属性
+type TwitterCard struct {
+	Card        string            `json:"card"`
+	Site        string            `json:"site"`
+	Creator     string            `json:"creator"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Image       string            `json:"image"`
+	Raw         map[string]string `json:"raw"`
+}
+
+// MicrodataItem 微数据或 RDFa 条目
+type MicrodataItem struct {
+	Type       []string                    `json:"type"`
+	ID         string                      `json:"id,omitempty"`
+	Properties map[string][]MicrodataValue `json:"properties"`
+}
+
+// MicrodataValue 属性值，嵌套条目时 Item 不为空
+type MicrodataValue struct {
+	Text string         `json:"text,omitempty"`
+	Item *MicrodataItem `json:"item,omitempty"`
+}
+
+// Get 获取属性的第一个文本值
+func (i MicrodataItem) Get(name string) string {
+	for _, v := range i.Properties[name] {
+		if v.Item == nil {
+			return v.Text
+		}
+	}
+	return ""
+}
+
+// Is 条目类型是否匹配，支持完整地址或短名称，例如 https://schema.org/Product 或 Product
+func (i MicrodataItem) Is(t string) bool {
+	for _, v := range i.Type {
+		if v == t || schemaName(v) == t {
+			return true
+		}
+	}
+	return false
+}
+
+var jsMetadata = `() => {
+	const absolute = (url) => {
+		try { return url ? new URL(url, document.baseURI).href : "" } catch (e) { return url }
+	};
+	const text = (el) => (el.textContent || "").replace(/\s+/g, " ").trim();
+
+	const metas = [];
+	for (const el of document.querySelectorAll("meta[content]")) {
+		const key = (el.getAttribute("property") || el.getAttribute("name") || el.getAttribute("itemprop") || "").trim().toLowerCase();
+		if (key) metas.push({ key, value: el.getAttribute("content").trim() });
+	}
+	const links = [];
+	for (const el of document.querySelectorAll("link[rel][href]")) {
+		links.push({
+			rel: el.getAttribute("rel").toLowerCase(), href: absolute(el.getAttribute("href")),
+			hreflang: el.getAttribute("hreflang") || "", type: el.getAttribute("type") || "",
+			title: el.getAttribute("title") || "", sizes: el.getAttribute("sizes") || "",
+		});
+	}
+
+	const value = (el, nested, item) => {
+		if (el.hasAttribute(nested)) return { item: item(el) };
+		if (el.hasAttribute("content")) return { text: el.getAttribute("content") };
+		switch (el.tagName) {
+		case "AUDIO": case "EMBED": case "IFRAME": case "IMG": case "SOURCE": case "TRACK": case "VIDEO":
+			return { text: absolute(el.getAttribute("src")) };
+		case "A": case "AREA": case "LINK":
+			return { text: absolute(el.getAttribute("href")) };
+		case "OBJECT":
+			return { text: absolute(el.getAttribute("data")) };
+		case "DATA": case "METER":
+			return { text: el.getAttribute("value") || text(el) };
+		case "TIME":
+			return { text: el.getAttribute("datetime") || text(el) };
+		}
+		return { text: text(el) };
+	};
+	const collect = (root, scope, prop, nested, item) => {
+		const properties = {};
+		for (const el of root.querySelectorAll("[" + prop + "]")) {
+			if (el.parentElement.closest(scope) !== root) continue;
+			for (const name of el.getAttribute(prop).trim().split(/\s+/)) {
+				(properties[name] = properties[name] || []).push(value(el, nested, item));
+			}
+		}
+		return properties;
+	};
+
+	const microdata = (el) => ({
+		type: (el.getAttribute("itemtype") || "").trim().split(/\s+/).filter(Boolean),
+		id: el.getAttribute("itemid") || "",
+		properties: collect(el, "[itemscope]", "itemprop", "itemscope", microdata),
+	});
+	const rdfa = (el) => {
+		const vocab = (el.closest("[vocab]") || { getAttribute: () => "" }).getAttribute("vocab");
+		return {
+			type: el.getAttribute("typeof").trim().split(/\s+/).filter(Boolean).map((t) => (/^\w+:/.test(t) || !vocab ? t : vocab + t)),
+			id: el.getAttribute("resource") || el.getAttribute("about") || "",
+			properties: collect(el, "[typeof]", "property", "typeof", rdfa),
+		};
+	};
+
+	const charset = document.querySelector("meta[charset]");
+	return {
+		title: document.title.trim(),
+		lang: document.documentElement.lang || "",
+		charset: charset ? charset.getAttribute("charset") : document.characterSet,
+		metas,
+		links,
+		jsonld: Array.from(document.querySelectorAll("script[type='application/ld+json' i]")).map((s) => s.textContent),
+		microdata: Array.from(document.querySelectorAll("[itemscope]:not([itemprop])")).map(microdata),
+		rdfa: Array.from(document.querySelectorAll("[typeof]:not([property])")).map(rdfa),
+	};
+}`
+
+type rawMetaTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type rawMetadata struct {
+	Title     string          `json:"title"`
+	Lang      string          `json:"lang"`
+	Charset   string          `json:"charset"`
+	Metas     []rawMetaTag    `json:"metas"`
+	Links     []MetaLink      `json:"links"`
+	JSONLD    []string        `json:"jsonld"`
+	Microdata []MicrodataItem `json:"microdata"`
+	RDFa      []MicrodataItem `json:"rdfa"`
+}
+
+// Metadata 提取页面的结构化元数据
+func (page *Page) Metadata() (*Metadata, error) {
+	res, err := page.Timeout().page.Eval(jsMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw rawMetadata
+	if err = res.Value.Unmarshal(&raw); err != nil {
+		return nil, err
+	}
+
+	return buildMetadata(raw), nil
+}
+
+var (
+	jsonLDComment  = regexp.MustCompile(`(?s)^\s*(<!--|<!\[CDATA\[)|(-->|\]\]>)\s*$`)
+	jsonLDTrailing = regexp.MustCompile(`,\s*([\]}])`)
+)
+
+func buildMetadata(raw rawMetadata) *Metadata {
+	m := &Metadata{
+		Title:   raw.Title,
+		Lang:    raw.Lang,
+		Charset: raw.Charset,
+		OpenGraph: OpenGraph{
+			Raw: map[string][]string{},
+		},
+		Twitter: TwitterCard{
+			Raw: map[string]string{},
+		},
+		Microdata: raw.Microdata,
+		RDFa:      raw.RDFa,
+	}
+
+	for _, v := range raw.Metas {
+		switch {
+		case v.Key == "description":
+			m.Description = v.Value
+		case v.Key == "keywords":
+			for _, k := range strings.Split(v.Value, ",") {
+				if k = strings.TrimSpace(k); k != "" {
+					m.Keywords = append(m.Keywords, k)
+				}
+			}
+		case v.Key == "robots" || v.Key == "googlebot":
+			for _, d := range strings.Split(v.Value, ",") {
+				if d = strings.ToLower(strings.TrimSpace(d)); d != "" && !zarray.Contains(m.Robots, d) {
+					m.Robots = append(m.Robots, d)
+				}
+			}
+		case strings.HasPrefix(v.Key, "twitter:"):
+			if _, ok := m.Twitter.Raw[v.Key]; !ok {
+				m.Twitter.Raw[v.Key] = v.Value
+			}
+		case strings.HasPrefix(v.Key, "og:"), strings.HasPrefix(v.Key, "article:"), strings.HasPrefix(v.Key, "product:"),
+			strings.HasPrefix(v.Key, "book:"), strings.HasPrefix(v.Key, "profile:"), strings.HasPrefix(v.Key, "video:"),
+			strings.HasPrefix(v.Key, "music:"):
+			m.OpenGraph.Raw[v.Key] = append(m.OpenGraph.Raw[v.Key], v.Value)
+		}
+	}
+
+	og := func(key string) string {
+		if v := m.OpenGraph.Raw[key]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	m.OpenGraph.Title = og("og:title")
+	m.OpenGraph.Type = og("og:type")
+	m.OpenGraph.URL = og("og:url")
+	m.OpenGraph.Description = og("og:description")
+	m.OpenGraph.SiteName = og("og:site_name")
+	m.OpenGraph.Locale = og("og:locale")
+	images := make([]string, 0, len(m.OpenGraph.Raw["og:image"])+len(m.OpenGraph.Raw["og:image:url"]))
+	images = append(images, m.OpenGraph.Raw["og:image"]...)
+	m.OpenGraph.Images = zarray.Unique(append(images, m.OpenGraph.Raw["og:image:url"]...))
+	videos := make([]string, 0, len(m.OpenGraph.Raw["og:video"])+len(m.OpenGraph.Raw["og:video:url"]))
+	videos = append(videos, m.OpenGraph.Raw["og:video"]...)
+	m.OpenGraph.Videos = zarray.Unique(append(videos, m.OpenGraph.Raw["og:video:url"]...))
+
+	tw := m.Twitter.Raw
+	m.Twitter.Card = tw["twitter:card"]
+	m.Twitter.Site = tw["twitter:site"]
+	m.Twitter.Creator = tw["twitter:creator"]
+	m.Twitter.Title = tw["twitter:title"]
+	m.Twitter.Description = tw["twitter:description"]
+	m.Twitter.Image = tw["twitter:image"]
+	if m.Twitter.Image == "" {
+		m.Twitter.Image = tw["twitter:image:src"]
+	}
+
+	for _, l := range raw.Links {
+		rels := strings.Fields(l.Rel)
+		switch {
+		case zarray.Contains(rels, "canonical"):
+			if m.Canonical == "" {
+				m.Canonical = l.Href
+			}
+		case zarray.Contains(rels, "alternate") && l.Hreflang != "":
+			m.Alternates = append(m.Alternates, l)
+		case zarray.Contains(rels, "alternate") && isFeedType(l.Type):
+			m.Feeds = append(m.Feeds, l)
+		case zarray.Contains(rels, "icon") || zarray.Contains(rels, "apple-touch-icon") ||
+			zarray.Contains(rels, "apple-touch-icon-precomposed") || zarray.Contains(rels, "mask-icon"):
+			m.Icons = append(m.Icons, l)
+		}
+	}
+
+	for _, s := range raw.JSONLD {
+		var v interface{}
+		s = jsonLDComment.ReplaceAllString(s, "")
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			if err = json.Unmarshal([]byte(jsonLDTrailing.ReplaceAllString(s, "$1")), &v); err != nil {
+				continue
+			}
+		}
+		m.JSONLD = append(m.JSONLD, v)
+	}
+
+	return m
+}
+
+func isFeedType(t string) bool {
+	t = strings.ToLower(t)
+	return strings.Contains(t, "rss") || strings.Contains(t, "atom") || strings.Contains(t, "feed+json")
+}
+
+// JSONLDNodes 展开 @graph 及数组，返回类型匹配的 JSON-LD 对象，types 为空时返回全部
+func (m *Metadata) JSONLDNodes(types ...string) []map[string]interface{} {
+	nodes := make([]map[string]interface{}, 0)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case []interface{}:
+			for i := range val {
+				walk(val[i])
+			}
+		case map[string]interface{}:
+			if graph, ok := val["@graph"]; ok {
+				walk(graph)
+			}
+			if _, ok := val["@type"]; ok && (len(types) == 0 || ldIs(val, types...)) {
+				nodes = append(nodes, val)
+			}
+		}
+	}
+	walk(m.JSONLD)
+	return nodes
+}
+
+// JSONLDProduct JSON-LD 商品
+type JSONLDProduct struct {
+	Name        string
+	Description string
+	SKU         string
+	Brand       string
+	URL         string
+	Images      []string
+	Offers      []JSONLDOffer
+	Rating      *JSONLDRating
+	Raw         map[string]interface{}
+}
+
+// JSONLDOffer 商品报价
+type JSONLDOffer struct {
+	Price         string
+	PriceCurrency string
+	Availability  string
+	URL           string
+	Seller        string
+}
+
+// JSONLDRating 商品评分
+type JSONLDRating struct {
+	Value float64
+	Count int
+}
+
+// Products JSON-LD 中的商品
+func (m *Metadata) Products() []JSONLDProduct {
+	nodes := m.JSONLDNodes("Product", "ProductGroup")
+	products := make([]JSONLDProduct, 0, len(nodes))
+	for _, n := range nodes {
+		p := JSONLDProduct{
+			Raw:         n,
+			Name:        ldString(n["name"]),
+			Description: ldString(n["description"]),
+			SKU:         ldString(n["sku"]),
+			Brand:       ldString(n["brand"]),
+			URL:         ldString(n["url"]),
+			Images:      ldStrings(n["image"]),
+		}
+
+		for _, o := range ldObjects(n["offers"]) {
+			if offers, ok := o["offers"]; ok && ldIs(o, "AggregateOffer") {
+				for _, sub := range ldObjects(offers) {
+					p.Offers = append(p.Offers, ldOffer(sub))
+				}
+				if len(ldObjects(offers)) > 0 {
+					continue
+				}
+			}
+			p.Offers = append(p.Offers, ldOffer(o))
+		}
+
+		if r := ldObjects(n["aggregateRating"]); len(r) > 0 {
+			count := ldString(r[0]["ratingCount"])
+			if count == "" {
+				count = ldString(r[0]["reviewCount"])
+			}
+			p.Rating = &JSONLDRating{}
+			p.Rating.Value, _ = strconv.ParseFloat(ldString(r[0]["ratingValue"]), 64)
+			p.Rating.Count, _ = strconv.Atoi(count)
+		}
+
+		products = append(products, p)
+	}
+	return products
+}
+
+func ldOffer(o map[string]interface{}) JSONLDOffer {
+	price := ldString(o["price"])
+	if price == "" {
+		price = ldString(o["lowPrice"])
+	}
+	return JSONLDOffer{
+		Price:         price,
+		PriceCurrency: ldString(o["priceCurrency"]),
+		Availability:  schemaName(ldString(o["availability"])),
+		URL:           ldString(o["url"]),
+		Seller:        ldString(o["seller"]),
+	}
+}
+
+// JSONLDArticle JSON-LD 文章
+type JSONLDArticle struct {
+	Type          string
+	Headline      string
+	Description   string
+	Publisher     string
+	DatePublished string
+	DateModified  string
+	URL           string
+	Authors       []string
+	Images        []string
+	Raw           map[string]interface{}
+}
+
+// Articles JSON-LD 中的文章，包括 NewsArticle、BlogPosting 等子类型
+func (m *Metadata) Articles() []JSONLDArticle {
+	nodes := m.JSONLDNodes("Article", "NewsArticle", "BlogPosting", "TechArticle", "ScholarlyArticle", "Report", "LiveBlogPosting")
+	articles := make([]JSONLDArticle, 0, len(nodes))
+	for _, n := range nodes {
+		headline := ldString(n["headline"])
+		if headline == "" {
+			headline = ldString(n["name"])
+		}
+		url := ldString(n["url"])
+		if url == "" {
+			url = ldString(n["mainEntityOfPage"])
+		}
+		articles = append(articles, JSONLDArticle{
+			Raw:           n,
+			Type:          ldString(n["@type"]),
+			Headline:      headline,
+			Description:   ldString(n["description"]),
+			Publisher:     ldString(n["publisher"]),
+			DatePublished: ldString(n["datePublished"]),
+			DateModified:  ldString(n["dateModified"]),
+			URL:           url,
+			Authors:       ldStrings(n["author"]),
+			Images:        ldStrings(n["image"]),
+		})
+	}
+	return articles
+}
+
+// BreadcrumbItem 面包屑条目
+type BreadcrumbItem struct {
+	Name     string
+	URL      string
+	Position int
+}
+
+// Breadcrumbs JSON-LD 中的面包屑导航，每个 BreadcrumbList 按 position 排序
+func (m *Metadata) Breadcrumbs() [][]BreadcrumbItem {
+	nodes := m.JSONLDNodes("BreadcrumbList")
+	lists := make([][]BreadcrumbItem, 0, len(nodes))
+	for _, n := range nodes {
+		items := make([]BreadcrumbItem, 0)
+		for i, e := range ldObjects(n["itemListElement"]) {
+			item := BreadcrumbItem{Name: ldString(e["name"]), URL: ldString(e["item"])}
+			if sub := ldObjects(e["item"]); len(sub) > 0 {
+				item.URL = ldString(sub[0]["@id"])
+				if item.URL == "" {
+					item.URL = ldString(sub[0]["url"])
+				}
+				if item.Name == "" {
+					item.Name = ldString(sub[0]["name"])
+				}
+			}
+			if p, err := strconv.Atoi(ldString(e["position"])); err == nil {
+				item.Position = p
+			} else {
+				item.Position = i + 1
+			}
+			items = append(items, item)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Position < items[j].Position
+		})
+		lists = append(lists, items)
+	}
+	return lists
+}
+
+func ldIs(n map[string]interface{}, types ...string) bool {
+	for _, t := range ldStrings(n["@type"]) {
+		t = schemaName(t)
+		for i := range types {
+			if t == types[i] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ldString 取 JSON-LD 值的字符串形式，对象取 name、@id 或 url，数组取第一个
+func ldString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return strings.TrimSpace(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []interface{}:
+		for i := range val {
+			if s := ldString(val[i]); s != "" {
+				return s
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"name", "@value", "url", "@id", "contentUrl"} {
+			if s := ldString(val[key]); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func ldStrings(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		list = []interface{}{v}
+	}
+
+	res := make([]string, 0, len(list))
+	for i := range list {
+		if s := ldString(list[i]); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func ldObjects(v interface{}) []map[string]interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{val}
+	case []interface{}:
+		res := make([]map[string]interface{}, 0, len(val))
+		for i := range val {
+			if m, ok := val[i].(map[string]interface{}); ok {
+				res = append(res, m)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// schemaName 去掉 schema.org 等词汇表前缀，例如 https://schema.org/InStock 为 InStock
+func schemaName(s string) string {
+	if i := strings.LastIndexAny(s, "/#:"); i >= 0 && i < len(s)-1 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestBuildMetadata(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	m := buildMetadata(rawMetadata{
+		Title: "Gopher",
+		Metas: []rawMetaTag{
+			{Key: "description", Value: "A gopher plush"},
+			{Key: "robots", Value: "NoIndex, follow"},
+			{Key: "og:title", Value: "Gopher Plush"},
+			{Key: "og:image", Value: "https://example.com/1.png"},
+			{Key: "og:image", Value: "https://example.com/2.png"},
+			{Key: "og:image:url", Value: "https://example.com/1.png"},
+			{Key: "og:video", Value: "https://example.com/1.mp4"},
+			{Key: "og:video:url", Value: "https://example.com/1.mp4"},
+			{Key: "og:video:url", Value: "https://example.com/2.mp4"},
+			{Key: "twitter:card", Value: "summary"},
+		},
+		Links: []MetaLink{
+			{Rel: "canonical", Href: "https://example.com/gopher"},
+			{Rel: "alternate", Hreflang: "zh", Href: "https://example.com/zh/gopher"},
+			{Rel: "alternate", Type: "application/rss+xml", Href: "https://example.com/feed"},
+			{Rel: "shortcut icon", Href: "https://example.com/favicon.ico"},
+		},
+		JSONLD: []string{
+			`<!-- {"@context": "https://schema.org", "@graph": [
+				{"@type": "Product", "name": "Gopher", "image": [{"url": "https://example.com/1.png"}],
+				 "offers": {"@type": "Offer", "price": 9.9, "priceCurrency": "USD", "availability": "https://schema.org/InStock"},
+				 "aggregateRating": {"ratingValue": "4.5", "reviewCount": 12}},
+				{"@type": "BreadcrumbList", "itemListElement": [
+					{"position": 2, "name": "Toys", "item": "https://example.com/toys"},
+					{"position": 1, "item": {"@id": "https://example.com/", "name": "Home"}},
+				]}
+			]} -->`,
+			`{invalid`,
+		},
+	})
+
+	tt.Equal("A gopher plush", m.Description)
+	tt.Equal([]string{"noindex", "follow"}, m.Robots)
+	tt.Equal("Gopher Plush", m.OpenGraph.Title)
+	tt.Equal([]string{"https://example.com/1.png", "https://example.com/2.png"}, m.OpenGraph.Images)
+	tt.Equal(2, len(m.OpenGraph.Raw["og:image"]))
+	tt.Equal([]string{"https://example.com/1.mp4", "https://example.com/2.mp4"}, m.OpenGraph.Videos)
+	tt.Equal([]string{"https://example.com/1.mp4"}, m.OpenGraph.Raw["og:video"])
+	tt.Equal("summary", m.Twitter.Card)
+	tt.Equal("https://example.com/gopher", m.Canonical)
+	tt.Equal(1, len(m.Alternates))
+	tt.Equal(1, len(m.Feeds))
+	tt.Equal(1, len(m.Icons))
+	tt.Equal(1, len(m.JSONLD))
+
+	products := m.Products()
+	tt.Equal(1, len(products))
+	tt.Equal("9.9", products[0].Offers[0].Price)
+	tt.Equal("InStock", products[0].Offers[0].Availability)
+	tt.Equal([]string{"https://example.com/1.png"}, products[0].Images)
+	tt.Equal(12, products[0].Rating.Count)
+
+	crumbs := m.Breadcrumbs()
+	tt.Equal(1, len(crumbs))
+	tt.Equal("Home", crumbs[0][0].Name)
+	tt.Equal("https://example.com/", crumbs[0][0].URL)
+	tt.Equal("https://example.com/toys", crumbs[0][1].URL)
+	tt.Equal(0, len(m.Articles()))
+}
@@ -23,6 +23,7 @@ import (
 type Options struct {
 	browser         *Browser
 	Hijack          HijackProcess
+	Politeness      *Politeness
 	Flags           map[string]string
 	Bin             string
 	WSEndpoint      string
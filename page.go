@@ -22,15 +22,37 @@ type Page struct {
 	ctx     context.Context
 	page    *rod.Page
 	browser *Browser
+	state   *pageState
+	owner   *Element
 	Options PageOptions
 	timeout time.Duration
 }
 
+// pageState 同一标签页的多个 Page 副本之间共享的状态
+type pageState struct {
+	har      *harRecorder
+	recorder *recorder
+	marks    map[int]*Mark
+	frames   map[proto.TargetTargetID]*rod.Page
+	popups   chan popupResult
+	releases []func() error
+	mu       sync.Mutex
+}
+
+var errPageState = errors.New("page state is not initialized")
+
+func (s *pageState) onRelease(fn func() error) {
+	s.mu.Lock()
+	s.releases = append(s.releases, fn)
+	s.mu.Unlock()
+}
+
 func (page *Page) FromROD(p *rod.Page) *Page {
 	return &Page{
 		page:    p,
 		browser: page.browser,
 		ctx:     page.ctx,
+		state:   page.state,
 		Options: page.Options,
 	}
 }
@@ -47,7 +69,38 @@ func (page *Page) Browser() *Browser {
 
 // Close 关闭页面
 func (page *Page) Close() error {
-	return page.page.Close()
+	err := page.Release()
+	if cerr := page.page.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// Release 释放页面的请求拦截、HAR 录制等资源，不会关闭页面，
+// 返回第一个释放失败的错误，其余错误记录到日志
+func (page *Page) Release() error {
+	if page.state == nil {
+		return nil
+	}
+
+	page.state.mu.Lock()
+	releases := page.state.releases
+	page.state.releases = nil
+	page.state.mu.Unlock()
+
+	var err error
+	for i := len(releases) - 1; i >= 0; i-- {
+		e := releases[i]()
+		if e == nil {
+			continue
+		}
+		if err == nil {
+			err = e
+		} else {
+			page.browser.log.Error(e)
+		}
+	}
+	return err
 }
 
 // Value 获取上下文
@@ -77,6 +130,16 @@ const (
 
 // WaitOpen 等待页面打开，注意手动关闭新页面
 func (page *Page) WaitOpen(openType OpenType, fn func() error, d ...time.Duration) (*Page, error) {
+	if openType == OpenTypeSpa {
+		err := page.WaitSpaNavigation(fn, func(o *SpaOptions) {
+			o.Timeout = page.GetTimeout(d...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+
 	var wait func() (*Page, error)
 	if openType == OpenTypeNewTab {
 		waitNavigation := page.waitOpen(d...)
@@ -88,6 +151,7 @@ func (page *Page) WaitOpen(openType OpenType, fn func() error, d ...time.Duratio
 
 			newPage := *page
 			newPage.page = nPage
+			newPage.state = &pageState{}
 			if newPage.ctx != nil {
 				newPage.page = newPage.page.Context(newPage.ctx)
 			}
@@ -237,13 +301,15 @@ func (page *Page) Timeout(d ...time.Duration) *Page {
 		page:    rpage,
 		Options: page.Options,
 		browser: page.browser,
+		state:   page.state,
+		owner:   page.owner,
 		timeout: timeout,
 	}
 }
 
 // HasElement 检查元素是否存在，不会等待元素出现
 func (page *Page) HasElement(selector string) (bool, *Element) {
-	has, ele, _ := page.page.Has(selector)
+	has, ele := hasElement(page.page, nil, selector)
 	if !has {
 		return false, nil
 	}
@@ -259,7 +325,7 @@ func (page *Page) Element(selector string, jsRegex ...string) (ele *Element, err
 	var e *rod.Element
 
 	if len(jsRegex) == 0 {
-		e, err = page.page.Element(selector)
+		e, err = queryElement(page.page, nil, selector)
 	} else {
 		e, err = page.page.ElementByJS(rod.Eval(selector, jsRegex[0]))
 	}
@@ -284,7 +350,7 @@ func (page *Page) MustElement(selector string, jsRegex ...string) (ele *Element)
 }
 
 func (page *Page) Elements(selector string, filter ...string) (elements Elements, has bool) {
-	_, err := page.Timeout().page.Element(selector)
+	_, err := queryElement(page.Timeout().page, nil, selector)
 	if err != nil {
 		if errors.Is(err, &rod.ElementNotFoundError{}) {
 			return Elements{}, false
@@ -292,7 +358,7 @@ func (page *Page) Elements(selector string, filter ...string) (elements Elements
 		return
 	}
 
-	es, _ := page.page.Elements(selector)
+	es, _ := queryElements(page.page, nil, selector)
 	has = len(es) > 0
 
 	f := filterElements(filter...)
@@ -323,70 +389,74 @@ type RaceElementFunc struct {
 	Handle  func(element *Element) (retry bool, err error)
 }
 
-// RaceElement 等待多个元素出现，返回第一个出现的元素
-func (page *Page) RaceElement(elements map[string]RaceElementFunc) (name string, ele *Element, err error) {
-	info, ierr := page.page.Info()
-	if ierr != nil {
-		err = ierr
+// raceElementRetry RaceElement 默认的最多尝试次数
+const raceElementRetry = 3
+
+// RaceElement 等待多个元素出现，返回第一个出现的元素，
+// 处理函数要求重试时重新打开页面，最多尝试 maxRetry 次，默认 3 次
+func (page *Page) RaceElement(elements map[string]RaceElementFunc, maxRetry ...int) (name string, ele *Element, err error) {
+	info, err := page.page.Info()
+	if err != nil {
 		return
 	}
 
-	race, retry := page.page.Race(), false
+	attempts := raceElementRetry
+	if len(maxRetry) > 0 && maxRetry[0] > 0 {
+		attempts = maxRetry[0]
+	}
+	race := page.Race().Retry(attempts-1, false, func(p *Page) error {
+		t := p.GetTimeout()
+		if err := p.Timeout(t).NavigateWaitLoad(info.URL); err != nil {
+			return err
+		}
+		_ = p.Timeout(t).WaitDOMStable(0.1)
+		return nil
+	})
 	for key := range elements {
-		k := key
-		v := elements[k]
-		race = race.ElementFunc(func(p *rod.Page) (*rod.Element, error) {
-			var (
-				ele *Element
-				has bool
-			)
-			err := zerror.TryCatch(func() (err error) {
-				has, ele = v.Element(&Page{page: p, ctx: page.ctx, Options: page.Options, browser: page.browser})
-				return err
-			})
-			if !has {
-				return nil, &rod.ElementNotFoundError{}
-			}
-			if err != nil {
-				elementNotFoundError := &rod.ElementNotFoundError{}
-				if err.Error() == elementNotFoundError.Error() {
-					return nil, elementNotFoundError
+		k, v := key, elements[key]
+		race.branches = append(race.branches, raceBranch{
+			name: k,
+			poll: func(p *Page) (interface{}, bool) {
+				var (
+					has bool
+					e   *Element
+				)
+				_ = zerror.TryCatch(func() error {
+					has, e = v.Element(p)
+					return nil
+				})
+				if !has || e == nil {
+					return nil, false
 				}
-				return nil, err
-			}
-			return ele.element, nil
-		}).MustHandle(func(element *rod.Element) {
-			name = k
-			ele = &Element{
-				element: element,
-				page:    page,
-			}
-			if v.Handle != nil {
+				return &Element{element: e.element.Context(page.page.GetContext()), page: page}, true
+			},
+			handle: func(value interface{}) error {
+				ele = value.(*Element)
+				if v.Handle == nil {
+					return nil
+				}
+
+				var (
+					retry bool
+					err   error
+				)
 				if e := zerror.TryCatch(func() error {
 					retry, err = v.Handle(ele)
 					return nil
 				}); e != nil {
 					retry = true
 				}
-			}
+				if retry {
+					return RaceRetry(err)
+				}
+				return err
+			},
 		})
 	}
 
-	_, doErr := race.Do()
-	if err == nil && doErr != nil {
-		err = doErr
-	}
-
+	name, err = race.Do()
 	if err != nil {
 		name = ""
-		if retry {
-			t := page.GetTimeout()
-			err = page.Timeout(t).NavigateWaitLoad(info.URL)
-			if err == nil {
-				_ = page.Timeout(t).WaitDOMStable(0.1)
-				return page.Timeout(t).RaceElement(elements)
-			}
-		}
 	}
 
 	return
@@ -420,15 +490,21 @@ func (page *Page) MustSearch(query string) (ele *Element) {
 type PageOptions struct {
 	Ctx            context.Context
 	Network        func(p *proto.NetworkEmulateNetworkConditions)
+	RecordHAR      func(o *HAROptions)
+	RecordVideo    func(o *RecordOptions)
+	OnPopup        func(popup *Page) error
 	Hijack         map[string]HijackProcess
 	Device         devices.Device
 	Timeout        time.Duration
 	MaxTime        time.Duration
+	Popup          PopupPolicy
+	replayHAR      *harReplayOptions
 	Keep           bool
 	TriggerFavicon bool
+	Force          bool
 }
 
-func (b *Browser) Open(url string, process func(*Page) error, opts ...func(o *PageOptions)) error {
+func (b *Browser) Open(url string, process func(*Page) error, opts ...func(o *PageOptions)) (err error) {
 	if b.err != nil {
 		return b.err
 	}
@@ -438,6 +514,81 @@ func (b *Browser) Open(url string, process func(*Page) error, opts ...func(o *Pa
 		return zerror.With(err, "failed to create a new tab")
 	}
 
+	o := zutil.Optional(PageOptions{
+		Timeout:        time.Second * 120,
+		TriggerFavicon: true,
+		// Device:  devices.LaptopWithMDPIScreen,
+	}, opts...)
+
+	p, err := b.setupPage(page, o)
+	if err != nil {
+		_ = page.Close()
+		return err
+	}
+
+	keep := o.Keep
+	defer func() {
+		if keep {
+			return
+		}
+		if rerr := p.Release(); err == nil {
+			err = rerr
+		}
+		_ = p.page.Close()
+	}()
+
+	if err = b.navigate(p, url); err != nil {
+		keep = false
+		p.markRecordingFailed()
+		return zerror.With(err, "failed to open the page")
+	}
+
+	if process == nil {
+		return nil
+	}
+
+	processErr := zerror.TryCatch(func() error {
+		if p.Options.MaxTime > 0 {
+			go func() {
+				timer := time.NewTimer(p.Options.MaxTime)
+				defer timer.Stop()
+				select {
+				case <-timer.C:
+					p.page.Close()
+				case <-p.ctx.Done():
+				case <-p.page.GetContext().Done():
+				}
+			}()
+		}
+
+		return process(p)
+	})
+	if processErr != nil {
+		p.markRecordingFailed()
+	}
+	return processErr
+}
+
+// navigate 打开地址，设置了 Politeness 时只在导航期间占用主机的并发名额，
+// 使 process 中可以继续请求同一主机
+func (b *Browser) navigate(p *Page, url string) error {
+	if b.options.Politeness != nil {
+		ctx := p.Options.Ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		release, err := b.options.Politeness.Wait(ctx, url)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	return p.NavigateLoad(url)
+}
+
+// setupPage 为标签页应用浏览器及页面配置（用户代理、设备、请求拦截、脚本等）
+func (b *Browser) setupPage(page *rod.Page, o PageOptions) (*Page, error) {
 	if b.userAgent != nil {
 		_ = page.SetUserAgent(b.userAgent)
 	}
@@ -446,111 +597,146 @@ func (b *Browser) Open(url string, process func(*Page) error, opts ...func(o *Pa
 		page:    page,
 		browser: b,
 		ctx:     page.GetContext(),
+		state:   &pageState{},
 	}
 
-	o := zutil.Optional(PageOptions{
-		Timeout:        time.Second * 120,
-		TriggerFavicon: true,
-		// Device:  devices.LaptopWithMDPIScreen,
-	}, opts...)
-	{
-		if o.Ctx != nil {
-			p.page = p.page.Context(o.Ctx)
-		}
+	if o.Ctx != nil {
+		p.page = p.page.Context(o.Ctx)
+	}
+
+	if o.TriggerFavicon {
+		_ = p.page.TriggerFavicon()
+	}
 
-		if o.TriggerFavicon {
-			_ = p.page.TriggerFavicon()
+	if o.Device.Title != "" {
+		if err := p.page.Emulate(o.Device); err != nil {
+			return nil, zerror.With(err, "failed to emulate device")
 		}
+	}
 
-		if o.Device.Title != "" {
-			p.page = p.page.MustEmulate(o.Device)
+	if o.Network != nil {
+		p.page.EnableDomain(proto.NetworkEnable{})
+		network := proto.NetworkEmulateNetworkConditions{
+			Offline:            false,
+			Latency:            0,
+			DownloadThroughput: -1,
+			UploadThroughput:   -1,
+			ConnectionType:     proto.NetworkConnectionTypeNone,
 		}
+		o.Network(&network)
+		_ = network.Call(p.page)
+	}
 
-		if o.Network != nil {
-			p.page.EnableDomain(proto.NetworkEnable{})
-			network := proto.NetworkEmulateNetworkConditions{
-				Offline:            false,
-				Latency:            0,
-				DownloadThroughput: -1,
-				UploadThroughput:   -1,
-				ConnectionType:     proto.NetworkConnectionTypeNone,
-			}
-			o.Network(&network)
-			_ = network.Call(p.page)
+	var replay *harReplay
+	if o.replayHAR != nil {
+		var err error
+		replay, err = newHARReplay(o.replayHAR, b.client.Client())
+		if err != nil {
+			return nil, zerror.With(err, "failed to load har")
 		}
+		p.state.onRelease(func() error {
+			if err := replay.save(); err != nil {
+				return zerror.With(err, "failed to save har")
+			}
+			return nil
+		})
+	}
 
-		if b.options.Hijack != nil || len(o.Hijack) > 0 {
-			stop := p.hijack(func(router *rod.HijackRouter) {
-				for k, v := range o.Hijack {
-					_ = router.Add(k, "", func(ctx *rod.Hijack) {
-						hijaclProcess(newHijacl(ctx, b.client), v)
-					})
-				}
+	if b.options.Hijack != nil || len(o.Hijack) > 0 || replay != nil {
+		stop := p.hijack(func(router *rod.HijackRouter) {
+			if replay != nil {
+				_ = router.Add("*", "", func(ctx *rod.Hijack) {
+					replay.handle(newHijacl(ctx, b.client))
+				})
+			}
 
-				if b.options.Hijack != nil {
-					_ = router.Add("*", "", func(ctx *rod.Hijack) {
-						hijaclProcess(newHijacl(ctx, b.client), b.options.Hijack)
-					})
-				}
+			for k, v := range o.Hijack {
+				_ = router.Add(k, "", func(ctx *rod.Hijack) {
+					hijaclProcess(newHijacl(ctx, b.client), v)
+				})
+			}
 
+			if b.options.Hijack != nil {
 				_ = router.Add("*", "", func(ctx *rod.Hijack) {
-					hijaclProcess(newHijacl(ctx, b.client), func(router *Hijack) (stop bool) {
-						return false
-					})
+					hijaclProcess(newHijacl(ctx, b.client), b.options.Hijack)
 				})
-			})
-			if !o.Keep {
-				defer func() {
-					_ = stop()
-				}()
 			}
-		}
-	}
 
-	defer func() {
-		if !o.Keep || err != nil {
-			_ = p.page.Close()
-		}
-	}()
+			_ = router.Add("*", "", func(ctx *rod.Hijack) {
+				hijaclProcess(newHijacl(ctx, b.client), func(router *Hijack) (stop bool) {
+					return false
+				})
+			})
+		})
+		p.state.onRelease(func() error {
+			_ = stop()
+			return nil
+		})
+	}
 
 	p.Options = o
 
 	if p.browser.options.Stealth && len(stealth) > 0 {
-		p.page.MustEvalOnNewDocument(`(()=>{` + stealth + `})()`)
+		if _, err := p.page.EvalOnNewDocument(`(()=>{` + stealth + `})()`); err != nil {
+			_ = p.Release()
+			return nil, err
+		}
 	}
 
 	for i := range b.options.Scripts {
-		p.page.MustEvalOnNewDocument(b.options.Scripts[i])
+		if _, err := p.page.EvalOnNewDocument(b.options.Scripts[i]); err != nil {
+			_ = p.Release()
+			return nil, err
+		}
 	}
 
-	if err = p.NavigateLoad(url); err != nil {
-		return zerror.With(err, "failed to open the page")
+	if err := p.watchPopup(o); err != nil {
+		_ = p.Release()
+		return nil, err
 	}
 
-	if process == nil {
-		return nil
+	if o.RecordHAR != nil {
+		if err := p.StartHAR(o.RecordHAR); err != nil {
+			_ = p.Release()
+			return nil, zerror.With(err, "failed to record har")
+		}
+		p.state.onRelease(func() error {
+			if _, err := p.StopHAR(); err != nil && !errors.Is(err, errHARNotStarted) {
+				return zerror.With(err, "failed to save har")
+			}
+			return nil
+		})
 	}
 
-	return zerror.TryCatch(func() error {
-		if p.Options.MaxTime > 0 {
-			go func() {
-				timer := time.NewTimer(p.Options.MaxTime)
-				defer timer.Stop()
-				select {
-				case <-timer.C:
-					p.page.Close()
-				case <-p.ctx.Done():
-				case <-p.page.GetContext().Done():
-				}
-			}()
+	if o.RecordVideo != nil {
+		if err := p.StartRecording(o.RecordVideo); err != nil {
+			_ = p.Release()
+			return nil, zerror.With(err, "failed to record video")
 		}
+		p.state.onRelease(func() error {
+			res, err := p.StopRecording()
+			if err != nil {
+				if errors.Is(err, errRecordingNotStarted) {
+					return nil
+				}
+				return zerror.With(err, "failed to save recording")
+			}
+			if res.File != "" {
+				b.log.Info("recording saved:", res.File)
+			} else if res.Dir != "" {
+				b.log.Info("recording frames saved:", res.Dir)
+			}
+			return nil
+		})
+	}
 
-		return process(p)
-	})
+	return p, nil
 }
 
 func hijaclProcess(h *Hijack, p HijackProcess) {
 	if h.CustomState != nil {
+		// 已被其他处理器（如 HAR 回放）响应，避免 rod 再次放行请求
+		h.Skip = true
 		return
 	}
 
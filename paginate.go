@@ -0,0 +1,196 @@
+package browser
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// ErrStopPaginate 在 Paginate 的处理函数中返回该错误可提前结束翻页，当前页不计入处理的页数
+var ErrStopPaginate = errors.New("stop paginate")
+
+// PaginateOptions 翻页选项，Next、Links、URL、LoadMore 按顺序取第一个非空的作为翻页方式
+type PaginateOptions struct {
+	// Next 下一页按钮选择器
+	Next string
+	// Links 页码链接选择器，按链接文本匹配下一页的页码
+	Links string
+	// URL 地址模板，{n} 会被替换为页码，例如 https://example.com/list?page={n}
+	URL string
+	// LoadMore 加载更多按钮选择器，内容会追加在当前页面
+	LoadMore string
+	// Content 内容区域选择器，用于检测翻页后内容是否变化，默认为 body
+	Content string
+	// Start URL 模板的起始页码，默认为 1
+	Start int
+	// MaxPages 最多处理的页数，0 为不限制
+	MaxPages int
+	// Wait 等待翻页后内容变化的超时时间，默认为页面超时时间
+	Wait time.Duration
+}
+
+// Paginate 逐页处理列表页，fn 接收当前页面及页码，
+// 下一页按钮不存在或已禁用、页码链接不存在、翻页后内容未变化时视为最后一页，返回处理的页数。
+// LoadMore 方式下每次传入的都是同一个页面，包含之前已处理的内容，只处理新增内容请使用 PaginateItems
+func (page *Page) Paginate(fn func(p *Page, n int) error, opts ...func(o *PaginateOptions)) (int, error) {
+	o, err := page.paginateOptions(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 1
+	if o.Next == "" && o.Links == "" && o.URL != "" {
+		n = o.Start
+		if err := page.NavigateWaitLoad(paginateURL(o.URL, n)); err != nil {
+			return 0, err
+		}
+	}
+
+	for count := 0; ; n++ {
+		if err := fn(page, n); err != nil {
+			if errors.Is(err, ErrStopPaginate) {
+				return count, nil
+			}
+			return count, err
+		}
+		count++
+
+		if o.MaxPages > 0 && count >= o.MaxPages {
+			return count, nil
+		}
+
+		before := page.paginateSignature(o.Content)
+		ok, err := page.paginateNext(o, n)
+		if err != nil {
+			return count, err
+		}
+		if !ok || !page.paginateChanged(o, before) {
+			return count, nil
+		}
+	}
+}
+
+// PaginateItems 逐页处理 selector 匹配的列表项，
+// LoadMore 方式下按之前的列表项数量只传入新增的列表项，其余同 Paginate
+func (page *Page) PaginateItems(selector string, fn func(items Elements, n int) error, opts ...func(o *PaginateOptions)) (int, error) {
+	o, err := page.paginateOptions(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	loadMore := o.Next == "" && o.Links == "" && o.URL == ""
+	processed := 0
+	return page.Paginate(func(p *Page, n int) error {
+		es, err := queryElements(p.page, nil, selector)
+		if err != nil {
+			return err
+		}
+
+		items := make(Elements, 0, len(es))
+		for i := range es {
+			if loadMore && i < processed {
+				continue
+			}
+			items = append(items, &Element{element: es[i], page: p})
+		}
+		processed = len(es)
+
+		return fn(items, n)
+	}, opts...)
+}
+
+func (page *Page) paginateOptions(opts ...func(o *PaginateOptions)) (PaginateOptions, error) {
+	o := zutil.Optional(PaginateOptions{Content: "body", Start: 1}, opts...)
+	if o.Next == "" && o.Links == "" && o.URL == "" && o.LoadMore == "" {
+		return o, errors.New("paginate requires one of Next, Links, URL or LoadMore")
+	}
+	if o.Wait == 0 {
+		o.Wait = page.GetTimeout()
+	}
+	return o, nil
+}
+
+// paginateNext 跳转到下一页，返回 false 表示已是最后一页
+func (page *Page) paginateNext(o PaginateOptions, n int) (bool, error) {
+	switch {
+	case o.Next != "":
+		return page.paginateClick(o.Next)
+	case o.Links != "":
+		next := strconv.Itoa(n + 1)
+		es, err := queryElements(page.page, nil, o.Links)
+		if err != nil {
+			return false, err
+		}
+		for i := range es {
+			text, err := es[i].Text()
+			if err != nil || strings.TrimSpace(text) != next {
+				continue
+			}
+			e := &Element{element: es[i], page: page}
+			return true, e.Click()
+		}
+		return false, nil
+	case o.URL != "":
+		return true, page.NavigateWaitLoad(paginateURL(o.URL, n+1))
+	default:
+		return page.paginateClick(o.LoadMore)
+	}
+}
+
+func (page *Page) paginateClick(selector string) (bool, error) {
+	has, ele := hasElement(page.page, nil, selector)
+	if !has {
+		return false, nil
+	}
+
+	disabled, err := ele.Eval(`() => this.disabled === true || this.getAttribute("aria-disabled") === "true" ||
+		this.classList.contains("disabled") || !!this.closest("[disabled], .disabled, [aria-disabled=true]")`)
+	if err != nil {
+		return false, err
+	}
+	if disabled.Value.Bool() {
+		return false, nil
+	}
+
+	if visible, _ := ele.Visible(); !visible {
+		return false, nil
+	}
+
+	e := &Element{element: ele, page: page}
+	return true, e.Click()
+}
+
+// paginateChanged 等待内容区域变化
+func (page *Page) paginateChanged(o PaginateOptions, before string) bool {
+	deadline := time.Now().Add(o.Wait)
+	for time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+		if now := page.paginateSignature(o.Content); now != "" && now != before {
+			_ = page.Timeout(o.Wait).WaitLoad()
+			return true
+		}
+	}
+
+	return false
+}
+
+func (page *Page) paginateSignature(selector string) string {
+	has, ele := hasElement(page.page, nil, selector)
+	if !has {
+		return ""
+	}
+
+	text, err := ele.Text()
+	if err != nil {
+		return ""
+	}
+	return zstring.Md5(text)
+}
+
+func paginateURL(tpl string, n int) string {
+	return strings.ReplaceAll(tpl, "{n}", strconv.Itoa(n))
+}
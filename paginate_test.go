@@ -0,0 +1,59 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestPaginateItemsLoadMore(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><ul id="list"><li>1</li><li>2</li></ul><button id="more">more</button><script>
+let n = 2;
+document.getElementById("more").onclick = () => {
+	for (let i = 0; i < 2; i++) {
+		const li = document.createElement("li");
+		li.textContent = String(++n);
+		document.getElementById("list").appendChild(li);
+	}
+	if (n >= 6) document.getElementById("more").disabled = true;
+};
+</script></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		var got [][]string
+		count, err := p.PaginateItems("#list li", func(items Elements, n int) error {
+			texts := make([]string, 0, len(items))
+			for _, v := range items {
+				text, err := v.ROD().Text()
+				if err != nil {
+					return err
+				}
+				texts = append(texts, text)
+			}
+			got = append(got, texts)
+			return nil
+		}, func(o *PaginateOptions) {
+			o.LoadMore = "#more"
+			o.Content = "#list"
+		})
+		if err != nil {
+			return err
+		}
+		tt.Equal(3, count)
+		tt.Equal([][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}}, got)
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+}
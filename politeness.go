@@ -0,0 +1,354 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// ErrDisallowed robots.txt 不允许访问
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// PolitenessOptions 礼貌抓取选项
+type PolitenessOptions struct {
+	// UserAgent 匹配 robots.txt 分组的爬虫名称，例如 MyBot，为空时只匹配 *
+	UserAgent string
+	// MinInterval 同一主机两次访问的最小间隔，默认 1s，robots.txt 的 Crawl-delay 更大时使用 Crawl-delay
+	MinInterval time.Duration
+	// MaxCrawlDelay Crawl-delay 的上限，默认 30s
+	MaxCrawlDelay time.Duration
+	// RobotsTTL robots.txt 缓存时间，默认 24h
+	RobotsTTL time.Duration
+	// MaxConcurrent 同一主机的最大并发数，默认 1
+	MaxConcurrent int
+	// IgnoreRobots 为 true 时不检查 robots.txt，只限制访问频率
+	IgnoreRobots bool
+}
+
+// Politeness 按主机限制访问频率、并发并遵守 robots.txt
+type Politeness struct {
+	client *zhttp.Engine
+	robots map[string]*Robots
+	hosts  map[string]*politeHost
+	o      PolitenessOptions
+	mu     sync.Mutex
+}
+
+type politeHost struct {
+	sem  chan struct{}
+	next time.Time
+}
+
+// NewPoliteness 创建礼貌抓取限制，通过 Options.Politeness 设置给浏览器后，
+// Browser.Open 和 Browser.Request 会在访问前等待
+func NewPoliteness(opts ...func(o *PolitenessOptions)) *Politeness {
+	o := zutil.Optional(PolitenessOptions{
+		MinInterval:   time.Second,
+		MaxCrawlDelay: 30 * time.Second,
+		RobotsTTL:     24 * time.Hour,
+		MaxConcurrent: 1,
+	}, opts...)
+	if o.MaxConcurrent <= 0 {
+		o.MaxConcurrent = 1
+	}
+
+	return &Politeness{
+		o:      o,
+		robots: make(map[string]*Robots),
+		hosts:  make(map[string]*politeHost),
+	}
+}
+
+// Robots 获取地址所在站点的 robots.txt，结果会被缓存
+func (p *Politeness) Robots(rawURL string) (*Robots, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	p.mu.Lock()
+	r, ok := p.robots[origin]
+	client := p.client
+	p.mu.Unlock()
+	if ok && time.Now().Before(r.expires) {
+		return r, nil
+	}
+	if client == nil {
+		client = zhttp.New()
+		p.mu.Lock()
+		p.client = client
+		p.mu.Unlock()
+	}
+
+	r = &Robots{expires: time.Now().Add(p.o.RobotsTTL)}
+	res, err := client.Get(origin + "/robots.txt")
+	switch {
+	case err != nil || res.StatusCode() >= 500:
+		// 无法访问 robots.txt 时视为全部禁止，稍后重试
+		r.disallowAll = true
+		r.expires = time.Now().Add(robotsRetry)
+	case res.StatusCode() >= 200 && res.StatusCode() < 300:
+		r = ParseRobots(res.String())
+		r.expires = time.Now().Add(p.o.RobotsTTL)
+	}
+
+	p.mu.Lock()
+	p.robots[origin] = r
+	p.mu.Unlock()
+	return r, nil
+}
+
+const robotsRetry = 5 * time.Minute
+
+// Allowed robots.txt 是否允许 userAgent 访问地址
+func (p *Politeness) Allowed(userAgent, rawURL string) (bool, error) {
+	if p.o.IgnoreRobots {
+		return true, nil
+	}
+
+	r, err := p.Robots(rawURL)
+	if err != nil {
+		return false, err
+	}
+	return r.Allowed(userAgent, rawURL), nil
+}
+
+// Wait 等待可以访问地址，返回的 release 需要在访问结束后调用，
+// robots.txt 不允许时返回 ErrDisallowed
+func (p *Politeness) Wait(ctx context.Context, rawURL string) (release func(), err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := p.o.MinInterval
+	if !p.o.IgnoreRobots {
+		r, err := p.Robots(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if !r.Allowed(p.o.UserAgent, rawURL) {
+			return nil, ErrDisallowed
+		}
+		if delay := r.CrawlDelay(p.o.UserAgent); delay > interval {
+			interval = delay
+			if p.o.MaxCrawlDelay > 0 && interval > p.o.MaxCrawlDelay {
+				interval = p.o.MaxCrawlDelay
+			}
+		}
+	}
+
+	host := strings.ToLower(u.Host)
+	p.mu.Lock()
+	h, ok := p.hosts[host]
+	if !ok {
+		h = &politeHost{sem: make(chan struct{}, p.o.MaxConcurrent)}
+		p.hosts[host] = h
+	}
+	p.mu.Unlock()
+
+	select {
+	case h.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release = func() {
+		<-h.sem
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	at := h.next
+	if at.Before(now) {
+		at = now
+	}
+	h.next = at.Add(interval)
+	p.mu.Unlock()
+
+	if wait := time.Until(at); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// Robots 解析后的 robots.txt
+type Robots struct {
+	expires     time.Time
+	groups      []robotsGroup
+	Sitemaps    []string
+	disallowAll bool
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int
+	allow   bool
+}
+
+// ParseRobots 解析 robots.txt 内容
+func ParseRobots(content string) *Robots {
+	r := &Robots{}
+	var (
+		group     *robotsGroup
+		lastAgent bool
+	)
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "user-agent":
+			if group == nil || !lastAgent {
+				r.groups = append(r.groups, robotsGroup{})
+				group = &r.groups[len(r.groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			lastAgent = true
+			continue
+		case "allow", "disallow":
+			if group != nil && value != "" {
+				group.rules = append(group.rules, robotsRule{
+					pattern: robotsPattern(value),
+					length:  len(value),
+					allow:   key == "allow",
+				})
+			}
+		case "crawl-delay":
+			if group != nil {
+				if f, err := strconv.ParseFloat(value, 64); err == nil && f > 0 {
+					group.crawlDelay = time.Duration(f * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			r.Sitemaps = append(r.Sitemaps, value)
+		}
+		lastAgent = false
+	}
+
+	return r
+}
+
+// robotsPattern 将 robots.txt 路径规则转为正则，支持 * 及结尾的 $
+func robotsPattern(value string) *regexp.Regexp {
+	end := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	parts := strings.Split(value, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if end {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// match 返回与 userAgent 匹配的分组，名称最长的优先，没有时使用 *
+func (r *Robots) match(userAgent string) []*robotsGroup {
+	userAgent = strings.ToLower(userAgent)
+	var (
+		groups   []*robotsGroup
+		wildcard []*robotsGroup
+		best     int
+	)
+	for i := range r.groups {
+		g := &r.groups[i]
+		for _, agent := range g.agents {
+			switch {
+			case agent == "*":
+				wildcard = append(wildcard, g)
+			case userAgent != "" && strings.Contains(userAgent, agent):
+				if len(agent) > best {
+					best, groups = len(agent), groups[:0]
+				}
+				if len(agent) == best {
+					groups = append(groups, g)
+				}
+			default:
+				continue
+			}
+			break
+		}
+	}
+	if len(groups) > 0 {
+		return groups
+	}
+	return wildcard
+}
+
+// Allowed 是否允许 userAgent 访问地址，匹配最长的规则，长度相同时 Allow 优先
+func (r *Robots) Allowed(userAgent, rawURL string) bool {
+	if r.disallowAll {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	p := u.EscapedPath()
+	if p == "" {
+		p = "/"
+	}
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	if p == "/robots.txt" {
+		return true
+	}
+
+	allowed, length := true, -1
+	for _, g := range r.match(userAgent) {
+		for _, rule := range g.rules {
+			if !rule.pattern.MatchString(p) {
+				continue
+			}
+			if rule.length > length || (rule.length == length && rule.allow) {
+				allowed, length = rule.allow, rule.length
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay 获取 userAgent 的 Crawl-delay
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, g := range r.match(userAgent) {
+		if g.crawlDelay > delay {
+			delay = g.crawlDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,103 @@
+package browser
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestParseRobots(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	r := ParseRobots(`# comment
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Disallow: /*.json$
+Crawl-delay: 2
+
+User-agent: MyBot
+User-agent: OtherBot
+Disallow: /
+Allow: /open
+Crawl-delay: 0.5
+
+Sitemap: https://example.com/sitemap.xml
+`)
+	tt.Equal([]string{"https://example.com/sitemap.xml"}, r.Sitemaps)
+
+	tt.EqualTrue(r.Allowed("", "https://example.com/"))
+	tt.EqualFalse(r.Allowed("", "https://example.com/private/a"))
+	tt.EqualTrue(r.Allowed("", "https://example.com/private/public/a"))
+	tt.EqualFalse(r.Allowed("", "https://example.com/data.json"))
+	tt.EqualTrue(r.Allowed("", "https://example.com/data.json?x=1"))
+	tt.Equal(2*time.Second, r.CrawlDelay("Googlebot"))
+
+	tt.EqualFalse(r.Allowed("Mozilla/5.0 (compatible; MyBot/1.0)", "https://example.com/private/public"))
+	tt.EqualTrue(r.Allowed("otherbot", "https://example.com/open/a"))
+	tt.EqualTrue(r.Allowed("MyBot", "https://example.com/robots.txt"))
+	tt.Equal(500*time.Millisecond, r.CrawlDelay("MyBot"))
+
+	tt.EqualTrue(ParseRobots("").Allowed("MyBot", "https://example.com/a"))
+}
+
+func TestPolitenessWait(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	p := NewPoliteness(func(o *PolitenessOptions) {
+		o.IgnoreRobots = true
+		o.MinInterval = 50 * time.Millisecond
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := p.Wait(context.Background(), "https://example.com/a")
+		tt.NoError(err)
+		release()
+	}
+	tt.EqualTrue(time.Since(start) >= 100*time.Millisecond)
+
+	release, err := p.Wait(context.Background(), "https://other.example.com/")
+	tt.NoError(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = p.Wait(ctx, "https://other.example.com/b")
+	tt.EqualTrue(err != nil)
+	release()
+}
+
+func TestPolitenessOpenRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<html><body>ok</body></html>")
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t, func(o *Options) {
+		o.Politeness = NewPoliteness(func(o *PolitenessOptions) {
+			o.MinInterval = 10 * time.Millisecond
+			o.MaxConcurrent = 1
+		})
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Open(srv.URL, func(p *Page) error {
+			_, err := p.Request("GET", srv.URL+"/api")
+			return err
+		})
+	}()
+
+	select {
+	case err := <-done:
+		tt.NoError(err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("request inside Open is blocked by politeness")
+	}
+}
@@ -0,0 +1,211 @@
+package browser
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zerror"
+)
+
+// PopupPolicy 弹窗（window.open、target=_blank）处理策略
+type PopupPolicy int
+
+const (
+	// PopupAllow 允许打开弹窗
+	PopupAllow PopupPolicy = iota
+	// PopupBlock 阻止打开弹窗
+	PopupBlock
+	// PopupSameTab 在当前标签页打开弹窗链接
+	PopupSameTab
+)
+
+var jsPopupPolicy = `(block) => {
+	const blank = t => !["", "_self", "_parent", "_top"].includes((t || "").toLowerCase());
+	window.open = function (url) {
+		if (block) return null;
+		if (url) location.href = new URL(url, location.href).href;
+		return window;
+	};
+	const redirect = (e, el) => {
+		if (!el || !blank(el.target)) return;
+		if (block) {
+			e.preventDefault();
+			return;
+		}
+		el.target = "_self";
+	};
+	document.addEventListener("click", e => {
+		redirect(e, e.target && e.target.closest && e.target.closest("a[target], area[target]"));
+	}, true);
+	document.addEventListener("submit", e => redirect(e, e.target), true);
+}`
+
+type popupResult struct {
+	page *Page
+	err  error
+}
+
+func (s *pageState) popupResults() chan popupResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.popups == nil {
+		s.popups = make(chan popupResult, 32)
+	}
+	return s.popups
+}
+
+// WaitPopup 等待下一个弹窗处理完成，返回弹窗页面及 OnPopup 的执行结果
+func (page *Page) WaitPopup(d ...time.Duration) (*Page, error) {
+	if page.state == nil {
+		return nil, errPageState
+	}
+
+	var timeout <-chan time.Time
+	if t := page.GetTimeout(d...); t > 0 {
+		timer := time.NewTimer(t)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case r := <-page.state.popupResults():
+		return r.page, r.err
+	case <-timeout:
+		return nil, context.DeadlineExceeded
+	}
+}
+
+// attachedSession 自动附加产生的会话
+type attachedSession struct {
+	*rod.Browser
+	id proto.TargetSessionID
+}
+
+func (s attachedSession) GetSessionID() proto.TargetSessionID {
+	return s.id
+}
+
+// watchPopup 按 PageOptions 的配置处理当前页面打开的弹窗，
+// 在页面会话上开启自动附加并暂停新目标，弹窗在开始加载前完成配置（脚本注入、拦截等）
+func (page *Page) watchPopup(o PageOptions) error {
+	if o.Popup != PopupAllow {
+		script := "(" + jsPopupPolicy + ")(" + strconv.FormatBool(o.Popup == PopupBlock) + ")"
+		if _, err := page.page.EvalOnNewDocument(script); err != nil {
+			return err
+		}
+	} else if o.OnPopup == nil {
+		return nil
+	}
+
+	rp := page.page
+	err := proto.TargetSetAutoAttach{
+		AutoAttach:             true,
+		WaitForDebuggerOnStart: true,
+		Flatten:                true,
+	}.Call(rp)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(rp.GetContext())
+	wait := rp.Context(ctx).EachEvent(func(e *proto.TargetAttachedToTarget) {
+		go page.popupAttached(e, o)
+	})
+	go wait()
+
+	page.state.onRelease(func() error {
+		cancel()
+		_ = proto.TargetSetAutoAttach{}.Call(rp)
+		return nil
+	})
+
+	return nil
+}
+
+// popupAttached 配置自动附加的弹窗后使其继续加载，iframe、worker 等其他目标直接继续
+func (page *Page) popupAttached(e *proto.TargetAttachedToTarget, o PageOptions) {
+	var after func()
+	info := e.TargetInfo
+	if e.WaitingForDebugger && info.Type == proto.TargetTargetInfoTypePage && info.OpenerID == page.page.TargetID {
+		after = page.configurePopup(info, o)
+	}
+
+	b := page.browser.Browser
+	if e.WaitingForDebugger {
+		_ = proto.RuntimeRunIfWaitingForDebugger{}.Call(attachedSession{b, e.SessionID})
+	}
+	_ = proto.TargetDetachFromTarget{SessionID: e.SessionID}.Call(page.page)
+
+	if after != nil {
+		after()
+	}
+}
+
+// configurePopup 在弹窗开始加载前按策略处理弹窗，返回弹窗继续加载后需要执行的操作
+func (page *Page) configurePopup(info *proto.TargetTargetInfo, o PageOptions) func() {
+	b := page.browser
+	switch o.Popup {
+	case PopupBlock:
+		_, _ = proto.TargetCloseTarget{TargetID: info.TargetID}.Call(b.Browser)
+		return nil
+	case PopupSameTab:
+		return func() {
+			u := info.URL
+			for i := 0; i < 20 && (u == "" || u == "about:blank"); i++ {
+				time.Sleep(100 * time.Millisecond)
+				res, err := proto.TargetGetTargetInfo{TargetID: info.TargetID}.Call(b.Browser)
+				if err != nil {
+					break
+				}
+				u = res.TargetInfo.URL
+			}
+			_, _ = proto.TargetCloseTarget{TargetID: info.TargetID}.Call(b.Browser)
+			if u != "" && u != "about:blank" {
+				_ = page.page.Navigate(u)
+			}
+		}
+	}
+
+	rp, err := b.Browser.PageFromTarget(info.TargetID)
+	if err != nil {
+		page.state.pushPopup(popupResult{err: err})
+		return nil
+	}
+
+	popupOptions := o
+	popupOptions.TriggerFavicon = false
+	popupOptions.RecordHAR = nil
+	popupOptions.RecordVideo = nil
+	popupOptions.replayHAR = nil
+	popup, err := b.setupPage(rp, popupOptions)
+	if err != nil {
+		_ = rp.Close()
+		page.state.pushPopup(popupResult{err: err})
+		return nil
+	}
+
+	return func() {
+		err := zerror.TryCatch(func() error {
+			return o.OnPopup(popup)
+		})
+		if rerr := popup.Release(); err == nil {
+			err = rerr
+		}
+		if !o.Keep {
+			_ = popup.page.Close()
+		}
+
+		page.state.pushPopup(popupResult{page: popup, err: err})
+	}
+}
+
+func (s *pageState) pushPopup(r popupResult) {
+	select {
+	case s.popupResults() <- r:
+	default:
+	}
+}
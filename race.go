@@ -0,0 +1,332 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/ysmood/gson"
+)
+
+// ErrRaceRetry 分支处理函数返回该错误时，竞争会在重试次数内重新开始
+var ErrRaceRetry = errors.New("race retry")
+
+type raceRetryError struct {
+	err error
+}
+
+func (e *raceRetryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *raceRetryError) Unwrap() error {
+	return e.err
+}
+
+func (e *raceRetryError) Is(target error) bool {
+	return target == ErrRaceRetry
+}
+
+// RaceRetry 包装错误，使竞争重新开始，重试次数用完后返回原错误信息
+func RaceRetry(err error) error {
+	if err == nil {
+		return ErrRaceRetry
+	}
+	return &raceRetryError{err: err}
+}
+
+// Race 竞争等待多个条件，第一个满足的分支获胜并执行其处理函数
+type Race struct {
+	page         *Page
+	before       func(p *Page) error
+	branches     []raceBranch
+	timeout      time.Duration
+	retry        int
+	retryTimeout bool
+}
+
+type raceBranch struct {
+	poll   func(p *Page) (interface{}, bool)
+	watch  func(p *rod.Page, hit func(v interface{})) func()
+	handle func(v interface{}) error
+	name   string
+}
+
+type raceHit struct {
+	value interface{}
+	index int
+}
+
+// Race 创建竞争等待
+func (page *Page) Race() *Race {
+	return &Race{page: page}
+}
+
+// Timeout 每轮竞争的超时时间，默认为页面超时时间
+func (r *Race) Timeout(d time.Duration) *Race {
+	r.timeout = d
+	return r
+}
+
+// Retry 处理函数返回 ErrRaceRetry 时最多重试 n 次，onTimeout 为 true 时超时也会重试，
+// 重试前执行 before，默认重新加载页面
+func (r *Race) Retry(n int, onTimeout bool, before ...func(p *Page) error) *Race {
+	r.retry = n
+	r.retryTimeout = onTimeout
+	if len(before) > 0 {
+		r.before = before[0]
+	}
+	return r
+}
+
+// Element 元素出现
+func (r *Race) Element(name, selector string, handle func(e *Element) error) *Race {
+	return r.element(name, selector, handle)
+}
+
+// Text 页面中出现指定文本
+func (r *Race) Text(name, text string, handle func(e *Element) error) *Race {
+	return r.element(name, "text="+text, handle)
+}
+
+func (r *Race) element(name, selector string, handle func(e *Element) error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		poll: func(p *Page) (interface{}, bool) {
+			has, ele := hasElement(p.page, nil, selector)
+			if !has {
+				return nil, false
+			}
+			return &Element{element: ele.Context(r.page.page.GetContext()), page: r.page}, true
+		},
+		handle: func(v interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle(v.(*Element))
+		},
+	})
+	return r
+}
+
+// ElementGone 元素消失或不存在
+func (r *Race) ElementGone(name, selector string, handle func() error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		poll: func(p *Page) (interface{}, bool) {
+			has, _, err := lookupElement(p.page, nil, selector)
+			if err != nil {
+				return nil, false
+			}
+			return nil, !has
+		},
+		handle: func(interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle()
+		},
+	})
+	return r
+}
+
+// URL 页面地址匹配，支持 * 通配符
+func (r *Race) URL(name, pattern string, handle func(url string) error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		poll: func(p *Page) (interface{}, bool) {
+			info, err := p.page.Info()
+			if err != nil || !zstring.Match(info.URL, pattern) {
+				return nil, false
+			}
+			return info.URL, true
+		},
+		handle: func(v interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle(v.(string))
+		},
+	})
+	return r
+}
+
+// JS 脚本返回真值，predicate 为 JS 函数，例如 () => window.loaded
+func (r *Race) JS(name, predicate string, handle func(value gson.JSON) error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		poll: func(p *Page) (interface{}, bool) {
+			res, err := p.page.Eval(predicate)
+			if err != nil {
+				return nil, false
+			}
+
+			v := res.Value
+			switch val := v.Val().(type) {
+			case nil:
+				return nil, false
+			case bool:
+				return v, val
+			case string:
+				return v, val != ""
+			case float64:
+				return v, val != 0
+			}
+			return v, true
+		},
+		handle: func(v interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle(v.(gson.JSON))
+		},
+	})
+	return r
+}
+
+// Response 收到地址匹配的响应，支持 * 通配符
+func (r *Race) Response(name, pattern string, handle func(res *proto.NetworkResponseReceived) error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		watch: func(p *rod.Page, hit func(v interface{})) func() {
+			return p.EachEvent(func(e *proto.NetworkResponseReceived) bool {
+				if !zstring.Match(e.Response.URL, pattern) {
+					return false
+				}
+				hit(e)
+				return true
+			})
+		},
+		handle: func(v interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle(v.(*proto.NetworkResponseReceived))
+		},
+	})
+	return r
+}
+
+// Dialog 弹出对话框（alert、confirm、prompt 等），处理函数需要自行关闭对话框
+func (r *Race) Dialog(name string, handle func(dialog *proto.PageJavascriptDialogOpening) error) *Race {
+	r.branches = append(r.branches, raceBranch{
+		name: name,
+		watch: func(p *rod.Page, hit func(v interface{})) func() {
+			return p.EachEvent(func(e *proto.PageJavascriptDialogOpening) bool {
+				hit(e)
+				return true
+			})
+		},
+		handle: func(v interface{}) error {
+			if handle == nil {
+				return nil
+			}
+			return handle(v.(*proto.PageJavascriptDialogOpening))
+		},
+	})
+	return r
+}
+
+// Do 开始竞争，返回获胜的分支名称
+func (r *Race) Do() (name string, err error) {
+	if len(r.branches) == 0 {
+		return "", errors.New("race has no branch")
+	}
+
+	for i := 0; ; i++ {
+		name, err = r.run()
+		if err == nil {
+			return
+		}
+
+		retry := errors.Is(err, ErrRaceRetry) || (r.retryTimeout && errors.Is(err, context.DeadlineExceeded))
+		if !retry || i >= r.retry {
+			return
+		}
+
+		before := r.before
+		if before == nil {
+			before = func(p *Page) error {
+				return p.Reload()
+			}
+		}
+		if err = before(r.page); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (r *Race) run() (string, error) {
+	timeout := r.timeout
+	if timeout == 0 {
+		timeout = r.page.GetTimeout()
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(r.page.page.GetContext(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(r.page.page.GetContext())
+	}
+	defer cancel()
+
+	p := r.page.FromROD(r.page.page.Context(ctx))
+	hits := make(chan raceHit, len(r.branches))
+	hit := func(i int) func(v interface{}) {
+		return func(v interface{}) {
+			select {
+			case hits <- raceHit{index: i, value: v}:
+			default:
+			}
+		}
+	}
+
+	polls := make([]int, 0, len(r.branches))
+	for i := range r.branches {
+		if r.branches[i].watch == nil {
+			polls = append(polls, i)
+			continue
+		}
+		go r.branches[i].watch(p.page, hit(i))()
+	}
+
+	if len(polls) > 0 {
+		go func() {
+			for {
+				for _, i := range polls {
+					if v, ok := r.branches[i].poll(p); ok {
+						hit(i)(v)
+						return
+					}
+					if ctx.Err() != nil {
+						return
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(100 * time.Millisecond):
+				}
+			}
+		}()
+	}
+
+	select {
+	case h := <-hits:
+		cancel()
+		b := r.branches[h.index]
+		return b.name, b.handle(h.value)
+	case <-ctx.Done():
+		if err := r.page.page.GetContext().Err(); err != nil {
+			return "", err
+		}
+		return "", context.DeadlineExceeded
+	}
+}
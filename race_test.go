@@ -0,0 +1,68 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestRaceElementRetry(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><body><div id="error">error</div></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	err := b.Open(srv.URL, func(p *Page) error {
+		attempts := 0
+		_, _, err := p.RaceElement(map[string]RaceElementFunc{
+			"error": {
+				Element: func(p *Page) (bool, *Element) {
+					return p.HasElement("#error")
+				},
+				Handle: func(element *Element) (bool, error) {
+					attempts++
+					return true, nil
+				},
+			},
+		})
+		tt.EqualTrue(errors.Is(err, ErrRaceRetry))
+		tt.Equal(raceElementRetry, attempts)
+
+		attempts = 0
+		_, _, err = p.RaceElement(map[string]RaceElementFunc{
+			"error": {
+				Element: func(p *Page) (bool, *Element) {
+					return p.HasElement("#error")
+				},
+				Handle: func(element *Element) (bool, error) {
+					attempts++
+					return true, errors.New("failed")
+				},
+			},
+		}, 2)
+		tt.EqualTrue(err != nil)
+		tt.Equal(2, attempts)
+
+		_, _, err = p.Timeout(time.Second).RaceElement(map[string]RaceElementFunc{
+			"none": {
+				Element: func(p *Page) (bool, *Element) {
+					return p.HasElement("#none")
+				},
+			},
+		})
+		tt.EqualTrue(errors.Is(err, context.DeadlineExceeded))
+		return nil
+	}, func(o *PageOptions) {
+		o.Timeout = 10 * time.Second
+	})
+	tt.NoError(err)
+}
@@ -0,0 +1,505 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/ysmood/gson"
+)
+
+// RecordFormat 录屏输出格式
+type RecordFormat string
+
+const (
+	// RecordGIF GIF 动图
+	RecordGIF RecordFormat = "gif"
+	// RecordAPNG APNG 动图
+	RecordAPNG RecordFormat = "apng"
+	// RecordMJPEG Motion JPEG，按帧率依次拼接的 jpeg
+	RecordMJPEG RecordFormat = "mjpeg"
+	// RecordFrames 只保留帧序列，不合成
+	RecordFrames RecordFormat = "frames"
+)
+
+// RecordOptions 录屏选项
+type RecordOptions struct {
+	// File 合成的文件，默认为 recordings 目录下以时间命名的文件
+	File string
+	// Dir 帧序列目录，文件名包含序号及时间戳，为空时使用临时目录并在合成后删除
+	Dir string
+	// Format 输出格式，默认根据 File 扩展名判断，否则为 gif
+	Format RecordFormat
+	// FPS 帧率，默认 10
+	FPS int
+	// MaxWidth 帧的最大宽度，默认 800
+	MaxWidth int
+	// MaxHeight 帧的最大高度，默认 600
+	MaxHeight int
+	// Quality 帧的 jpeg 质量，0-100，默认 80
+	Quality int
+	// OnlyOnFailure 只在失败时保留录屏，通过 Browser.Open 打开的页面 process 返回错误即为失败
+	OnlyOnFailure bool
+}
+
+var errRecordingNotStarted = errors.New("recording has not started")
+
+// Recording 录屏结果
+type Recording struct {
+	// File 合成的文件，未保留或只保留帧序列时为空
+	File string `json:"file,omitempty"`
+	// Dir 帧序列目录，未保留或使用临时目录时为空
+	Dir string `json:"dir,omitempty"`
+	// Frames 收到的帧数
+	Frames int `json:"frames"`
+	// Duration 录制时长
+	Duration time.Duration `json:"duration"`
+	// Kept 是否保留了录屏
+	Kept bool `json:"kept"`
+}
+
+type recorder struct {
+	page    *rod.Page
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started time.Time
+	dir     string
+	frames  []recordFrame
+	options RecordOptions
+	tempDir bool
+	failed  bool
+	mu      sync.Mutex
+}
+
+type recordFrame struct {
+	at   time.Time
+	file string
+}
+
+// recordStep 合成时的一帧及其持续时间
+type recordStep struct {
+	frame int
+	delay time.Duration
+}
+
+// recordFormat 根据文件扩展名判断录屏格式
+func recordFormat(file string) RecordFormat {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png", ".apng":
+		return RecordAPNG
+	case ".mjpeg", ".mjpg":
+		return RecordMJPEG
+	}
+	return RecordGIF
+}
+
+// StartRecording 通过 CDP screencast 开始录屏，帧序列实时写入磁盘
+func (page *Page) StartRecording(opts ...func(o *RecordOptions)) error {
+	if page.state == nil {
+		return errPageState
+	}
+
+	o := zutil.Optional(RecordOptions{
+		FPS:       10,
+		MaxWidth:  800,
+		MaxHeight: 600,
+		Quality:   80,
+	}, opts...)
+	if o.Format == "" {
+		o.Format = recordFormat(o.File)
+	}
+	if o.FPS <= 0 {
+		o.FPS = 10
+	}
+	name := filepath.Join("recordings", time.Now().Format("20060102-150405.000"))
+	switch {
+	case o.Format == RecordFrames:
+		if o.Dir == "" {
+			o.Dir = name
+		}
+	case o.File == "":
+		ext := string(o.Format)
+		if o.Format == RecordAPNG {
+			ext = "png"
+		}
+		o.File = name + "." + ext
+	}
+
+	page.state.mu.Lock()
+	defer page.state.mu.Unlock()
+	if page.state.recorder != nil {
+		return errors.New("recording has already started")
+	}
+
+	r := &recorder{
+		done:    make(chan struct{}),
+		started: time.Now(),
+		options: o,
+		dir:     o.Dir,
+	}
+	if r.dir == "" {
+		dir, err := os.MkdirTemp("", "zlsgo-recording-")
+		if err != nil {
+			return err
+		}
+		r.dir, r.tempDir = dir, true
+	} else {
+		r.dir = zfile.RealPathMkdir(r.dir)
+	}
+
+	base := page.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithCancel(base)
+	r.page, r.cancel = page.page.Context(base), cancel
+
+	wait := page.page.Context(ctx).EachEvent(r.frame)
+	go func() {
+		defer close(r.done)
+		wait()
+	}()
+
+	err := proto.PageStartScreencast{
+		Format:        proto.PageStartScreencastFormatJpeg,
+		Quality:       gson.Int(o.Quality),
+		MaxWidth:      gson.Int(o.MaxWidth),
+		MaxHeight:     gson.Int(o.MaxHeight),
+		EveryNthFrame: gson.Int(1),
+	}.Call(r.page)
+	if err != nil {
+		cancel()
+		<-r.done
+		r.discard()
+		return err
+	}
+
+	page.state.recorder = r
+	return nil
+}
+
+// StopRecording 停止录屏并合成，failed 为 true 时标记为失败，
+// 设置了 OnlyOnFailure 且没有失败时删除录屏
+func (page *Page) StopRecording(failed ...bool) (*Recording, error) {
+	if page.state == nil {
+		return nil, errPageState
+	}
+
+	page.state.mu.Lock()
+	r := page.state.recorder
+	page.state.recorder = nil
+	page.state.mu.Unlock()
+	if r == nil {
+		return nil, errRecordingNotStarted
+	}
+
+	_ = proto.PageStopScreencast{}.Call(r.page)
+	r.cancel()
+	<-r.done
+
+	end := time.Now()
+	res := &Recording{Frames: len(r.frames), Duration: end.Sub(r.started)}
+	if len(failed) > 0 && failed[0] {
+		r.failed = true
+	}
+	if (r.options.OnlyOnFailure && !r.failed) || len(r.frames) == 0 {
+		r.discard()
+		return res, nil
+	}
+
+	res.Kept = true
+	if !r.tempDir {
+		res.Dir = r.dir
+	}
+	if r.options.Format == RecordFrames {
+		return res, nil
+	}
+
+	defer func() {
+		if r.tempDir {
+			_ = os.RemoveAll(r.dir)
+		}
+	}()
+
+	res.File = zfile.RealPath(r.options.File)
+	steps := recordTimeline(r.frames, end, r.options.FPS)
+	return res, assembleRecording(res.File, r.options, r.frames, steps)
+}
+
+// markRecordingFailed 标记录屏为失败
+func (page *Page) markRecordingFailed() {
+	if page.state == nil {
+		return
+	}
+
+	page.state.mu.Lock()
+	if page.state.recorder != nil {
+		page.state.recorder.failed = true
+	}
+	page.state.mu.Unlock()
+}
+
+func (r *recorder) frame(e *proto.PageScreencastFrame) {
+	_ = proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(r.page)
+
+	at := time.Now()
+	if e.Metadata != nil && e.Metadata.Timestamp > 0 {
+		at = e.Metadata.Timestamp.Time()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file := filepath.Join(r.dir, fmt.Sprintf("frame-%06d-%d.jpg", len(r.frames)+1, at.UnixNano()/int64(time.Millisecond)))
+	if err := os.WriteFile(file, e.Data, 0o644); err != nil {
+		return
+	}
+	r.frames = append(r.frames, recordFrame{at: at, file: file})
+}
+
+// discard 删除已写入的帧
+func (r *recorder) discard() {
+	if r.tempDir {
+		_ = os.RemoveAll(r.dir)
+		return
+	}
+	for i := range r.frames {
+		_ = os.Remove(r.frames[i].file)
+	}
+}
+
+// recordTimeline 按帧率对帧重新采样，每个时间点使用此前最新的一帧，
+// 相邻的相同帧会合并为一步
+func recordTimeline(frames []recordFrame, end time.Time, fps int) []recordStep {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	interval := time.Second / time.Duration(fps)
+	steps := make([]recordStep, 0, len(frames))
+	i := 0
+	for t := frames[0].at; ; t = t.Add(interval) {
+		for i+1 < len(frames) && !frames[i+1].at.After(t) {
+			i++
+		}
+		if n := len(steps); n > 0 && steps[n-1].frame == i {
+			steps[n-1].delay += interval
+		} else {
+			steps = append(steps, recordStep{frame: i, delay: interval})
+		}
+		if !t.Add(interval).Before(end) {
+			break
+		}
+	}
+	return steps
+}
+
+// assembleRecording 将帧序列合成为动图或 MJPEG
+func assembleRecording(file string, o RecordOptions, frames []recordFrame, steps []recordStep) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	switch o.Format {
+	case RecordMJPEG:
+		err = writeMJPEG(w, frames, steps, o.FPS)
+	case RecordAPNG:
+		err = writeAPNG(w, frames, steps)
+	default:
+		err = writeGIF(w, frames, steps)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func writeMJPEG(w io.Writer, frames []recordFrame, steps []recordStep, fps int) error {
+	interval := time.Second / time.Duration(fps)
+	for _, s := range steps {
+		data, err := os.ReadFile(frames[s.frame].file)
+		if err != nil {
+			return err
+		}
+		for n := s.delay / interval; n > 0; n-- {
+			if _, err = w.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadFrames 依次解码帧并绘制到与第一帧相同尺寸的画布上
+func loadFrames(frames []recordFrame, steps []recordStep, fn func(img *image.RGBA, delay time.Duration) error) error {
+	var bounds image.Rectangle
+	for i, s := range steps {
+		data, err := os.ReadFile(frames[s.frame].file)
+		if err != nil {
+			return err
+		}
+		src, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			bounds = image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy())
+		}
+
+		img := image.NewRGBA(bounds)
+		draw.Draw(img, bounds, image.White, image.Point{}, draw.Src)
+		draw.Draw(img, bounds, src, src.Bounds().Min, draw.Src)
+		if err = fn(img, s.delay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGIF(w io.Writer, frames []recordFrame, steps []recordStep) error {
+	g := &gif.GIF{}
+	err := loadFrames(frames, steps, func(img *image.RGBA, delay time.Duration) error {
+		p := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(p, img.Bounds(), img, image.Point{})
+		g.Image = append(g.Image, p)
+		g.Delay = append(g.Delay, int(delay/(10*time.Millisecond)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return gif.EncodeAll(w, g)
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+func writeAPNG(w io.Writer, frames []recordFrame, steps []recordStep) error {
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+
+	seq := uint32(0)
+	err := loadFrames(frames, steps, func(img *image.RGBA, delay time.Duration) error {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		ihdr, idat, err := pngChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		first := seq == 0
+		if first {
+			if err = writePNGChunk(w, "IHDR", ihdr); err != nil {
+				return err
+			}
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl, uint32(len(steps)))
+			if err = writePNGChunk(w, "acTL", actl); err != nil {
+				return err
+			}
+		}
+
+		size := img.Bounds().Size()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+		num, den := delay/time.Millisecond, 1000
+		if num > 0xFFFF {
+			num, den = delay/(10*time.Millisecond), 100
+			if num > 0xFFFF {
+				num = 0xFFFF
+			}
+		}
+		binary.BigEndian.PutUint16(fctl[20:], uint16(num))
+		binary.BigEndian.PutUint16(fctl[22:], uint16(den))
+		if err = writePNGChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+		seq++
+
+		if first {
+			return writePNGChunk(w, "IDAT", idat)
+		}
+		fdat := make([]byte, 4, 4+len(idat))
+		binary.BigEndian.PutUint32(fdat, seq)
+		seq++
+		return writePNGChunk(w, "fdAT", append(fdat, idat...))
+	})
+	if err != nil {
+		return err
+	}
+	return writePNGChunk(w, "IEND", nil)
+}
+
+// pngChunks 获取 png 的 IHDR 及合并后的 IDAT 数据
+func pngChunks(data []byte) (ihdr, idat []byte, err error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, nil, errors.New("invalid png")
+	}
+	data = data[len(pngSignature):]
+	for len(data) >= 12 {
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data) < 12+n {
+			break
+		}
+		switch string(data[4:8]) {
+		case "IHDR":
+			ihdr = data[8 : 8+n]
+		case "IDAT":
+			idat = append(idat, data[8:8+n]...)
+		}
+		data = data[12+n:]
+	}
+	if ihdr == nil || idat == nil {
+		return nil, nil, errors.New("invalid png")
+	}
+	return ihdr, idat, nil
+}
+
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], typ)
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(header[4:])
+	_, _ = crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+
+	for _, b := range [][]byte{header, data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
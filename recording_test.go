@@ -0,0 +1,89 @@
+package browser
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestRecordTimeline(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	start := time.Now()
+	frames := []recordFrame{
+		{at: start},
+		{at: start.Add(50 * time.Millisecond)},
+		{at: start.Add(120 * time.Millisecond)},
+		{at: start.Add(450 * time.Millisecond)},
+	}
+	steps := recordTimeline(frames, start.Add(time.Second), 10)
+	tt.Equal([]recordStep{
+		{frame: 0, delay: 100 * time.Millisecond},
+		{frame: 1, delay: 100 * time.Millisecond},
+		{frame: 2, delay: 300 * time.Millisecond},
+		{frame: 3, delay: 500 * time.Millisecond},
+	}, steps)
+
+	tt.Equal([]recordStep{{frame: 0, delay: 100 * time.Millisecond}}, recordTimeline(frames[:1], start, 10))
+	tt.Equal(RecordAPNG, recordFormat("a.png"))
+	tt.Equal(RecordMJPEG, recordFormat("a.mjpg"))
+	tt.Equal(RecordGIF, recordFormat(""))
+}
+
+func TestAssembleRecording(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	dir := t.TempDir()
+
+	frames := make([]recordFrame, 0, 3)
+	for i, c := range []color.Gray{{Y: 0}, {Y: 128}, {Y: 255}} {
+		img := image.NewGray(image.Rect(0, 0, 8, 6))
+		for p := range img.Pix {
+			img.Pix[p] = c.Y
+		}
+		var buf bytes.Buffer
+		tt.NoError(jpeg.Encode(&buf, img, nil))
+		file := filepath.Join(dir, fmt.Sprintf("frame-%d.jpg", i))
+		tt.NoError(os.WriteFile(file, buf.Bytes(), 0o644))
+		frames = append(frames, recordFrame{file: file})
+	}
+	steps := []recordStep{
+		{frame: 0, delay: 100 * time.Millisecond},
+		{frame: 1, delay: 300 * time.Millisecond},
+		{frame: 2, delay: 200 * time.Millisecond},
+	}
+
+	file := filepath.Join(dir, "out.gif")
+	tt.NoError(assembleRecording(file, RecordOptions{Format: RecordGIF, FPS: 10}, frames, steps))
+	f, err := os.Open(file)
+	tt.NoError(err)
+	g, err := gif.DecodeAll(f)
+	_ = f.Close()
+	tt.NoError(err)
+	tt.Equal([]int{10, 30, 20}, g.Delay)
+
+	file = filepath.Join(dir, "out.png")
+	tt.NoError(assembleRecording(file, RecordOptions{Format: RecordAPNG, FPS: 10}, frames, steps))
+	data, err := os.ReadFile(file)
+	tt.NoError(err)
+	img, err := png.Decode(bytes.NewReader(data))
+	tt.NoError(err)
+	tt.Equal(image.Rect(0, 0, 8, 6), img.Bounds())
+	tt.Equal(2, bytes.Count(data, []byte("fdAT")))
+	tt.Equal(3, bytes.Count(data, []byte("fcTL")))
+
+	file = filepath.Join(dir, "out.mjpeg")
+	tt.NoError(assembleRecording(file, RecordOptions{Format: RecordMJPEG, FPS: 10}, frames, steps))
+	data, err = os.ReadFile(file)
+	tt.NoError(err)
+	tt.Equal(6, bytes.Count(data, []byte{0xFF, 0xD8, 0xFF}))
+}
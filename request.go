@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,8 +9,26 @@ import (
 	"github.com/sohaha/zlsgo/zhttp"
 )
 
-// Request 发起请求
+// Request 发起请求，v 中的 context.Context 同时用于取消 Politeness 的等待
 func (b *Browser) Request(method, url string, v ...interface{}) (*zhttp.Res, error) {
+	ctx := context.Background()
+	for i := range v {
+		if c, ok := v[i].(context.Context); ok {
+			ctx = c
+		}
+	}
+	return b.request(ctx, method, url, v...)
+}
+
+func (b *Browser) request(ctx context.Context, method, url string, v ...interface{}) (*zhttp.Res, error) {
+	if b.options.Politeness != nil {
+		release, err := b.options.Politeness.Wait(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	for _, cookie := range b.cookies {
 		v = append(v, cookie)
 	}
@@ -25,7 +44,7 @@ func (b *Browser) Request(method, url string, v ...interface{}) (*zhttp.Res, err
 
 // Request 发起请求
 func (page *Page) Request(method, url string, v ...interface{}) (*zhttp.Res, error) {
-	return page.browser.Request(method, url, v...)
+	return page.browser.request(page.page.GetContext(), method, url, v...)
 }
 
 // SavePageCookie 保存页面 cookie
@@ -0,0 +1,310 @@
+package browser
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/ysmood/gson"
+)
+
+// ScreenshotOptions 截图选项
+type ScreenshotOptions struct {
+	// Clip 截取区域，为页面坐标，不设置时截取可视区域、整个页面或元素
+	Clip *proto.PageViewport
+	// Format 图片格式，默认根据文件扩展名判断，否则为 png
+	Format proto.PageCaptureScreenshotFormat
+	// MaskColor 遮罩颜色，默认 #FF00FF
+	MaskColor string
+	// Mask 截图时用遮罩覆盖的元素选择器，例如隐私信息
+	Mask []string
+	// Hide 截图时隐藏的元素选择器
+	Hide []string
+	// Quality jpeg 及 webp 的图片质量，0-100
+	Quality int
+	// Scale 缩放比例，默认为 1
+	Scale float64
+	// FullPage 截取整个页面
+	FullPage bool
+	// OmitBackground 透明背景，仅 png 及 webp 有效
+	OmitBackground bool
+	// KeepCaret 保留输入光标，默认隐藏
+	KeepCaret bool
+	// KeepAnimations 保留动画，默认结束有限动画并暂停无限动画
+	KeepAnimations bool
+}
+
+var jsScreenshotPrepare = `(o, ...elements) => {
+	const restores = [];
+	const style = document.createElement("style");
+	let css = "";
+	if (!o.caret) css += "*, *::before, *::after { caret-color: transparent !important; }";
+	if (!o.animations) {
+		css += "*, *::before, *::after { transition: none !important; }";
+		for (const a of document.getAnimations()) {
+			const timing = a.effect && a.effect.getComputedTiming();
+			if (timing && timing.iterations === Infinity) {
+				a.pause();
+				restores.push(() => a.play());
+			} else {
+				try { a.finish() } catch (e) {}
+			}
+		}
+	}
+	style.textContent = css;
+	document.documentElement.appendChild(style);
+	restores.push(() => style.remove());
+
+	elements.slice(0, o.hide).forEach((el) => {
+		const value = el.style.getPropertyValue("visibility"), priority = el.style.getPropertyPriority("visibility");
+		el.style.setProperty("visibility", "hidden", "important");
+		restores.push(() => el.style.setProperty("visibility", value, priority));
+	});
+
+	const root = document.createElement("div");
+	root.style.cssText = "position: absolute; left: 0; top: 0; width: 0; height: 0; overflow: visible; z-index: 2147483647; pointer-events: none;";
+	for (const el of elements.slice(o.hide)) {
+		for (const r of el.getClientRects()) {
+			const mask = document.createElement("div");
+			mask.style.cssText = "position: absolute; left: " + (r.left + scrollX) + "px; top: " + (r.top + scrollY) + "px; width: " +
+				r.width + "px; height: " + r.height + "px; background: " + o.color + ";";
+			root.appendChild(mask);
+		}
+	}
+	document.documentElement.appendChild(root);
+	restores.push(() => root.remove());
+
+	window.__zlsScreenshotRestore = () => {
+		restores.reverse().forEach((fn) => fn());
+		delete window.__zlsScreenshotRestore;
+	};
+}`
+
+var jsScreenshotRestore = `() => window.__zlsScreenshotRestore && window.__zlsScreenshotRestore()`
+
+// screenshotFormat 根据文件扩展名判断图片格式
+func screenshotFormat(file string) proto.PageCaptureScreenshotFormat {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".jpg", ".jpeg":
+		return proto.PageCaptureScreenshotFormatJpeg
+	case ".webp":
+		return proto.PageCaptureScreenshotFormatWebp
+	}
+	return proto.PageCaptureScreenshotFormatPng
+}
+
+func screenshotFile(file string, opts []func(o *ScreenshotOptions)) []func(o *ScreenshotOptions) {
+	return append([]func(o *ScreenshotOptions){func(o *ScreenshotOptions) {
+		o.Format = screenshotFormat(file)
+	}}, opts...)
+}
+
+func screenshotOptions(opts []func(o *ScreenshotOptions)) ScreenshotOptions {
+	return zutil.Optional(ScreenshotOptions{
+		Format:    proto.PageCaptureScreenshotFormatPng,
+		MaskColor: "#FF00FF",
+		Scale:     1,
+	}, opts...)
+}
+
+// prepareScreenshot 在当前页面中隐藏、遮罩元素并暂停动画，返回恢复函数
+func (p *Page) prepareScreenshot(o ScreenshotOptions) (func(), error) {
+	page := p.Timeout().page
+	args := []interface{}{nil}
+	hide := 0
+	for n, selectors := range [][]string{o.Hide, o.Mask} {
+		for _, s := range selectors {
+			es, err := queryElements(page, nil, s)
+			if err != nil {
+				return nil, err
+			}
+			for i := range es {
+				args = append(args, es[i].Object)
+			}
+		}
+		if n == 0 {
+			hide = len(args) - 1
+		}
+	}
+	args[0] = map[string]interface{}{
+		"caret":      o.KeepCaret,
+		"animations": o.KeepAnimations,
+		"color":      o.MaskColor,
+		"hide":       hide,
+	}
+
+	if _, err := page.Eval(jsScreenshotPrepare, args...); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = page.Eval(jsScreenshotRestore)
+	}, nil
+}
+
+// capture 截图，ele 不为空时截取元素区域，同时返回截取区域的页面坐标
+func (p *Page) capture(ele *Element, opts ...func(o *ScreenshotOptions)) ([]byte, *proto.PageViewport, error) {
+	o := screenshotOptions(opts)
+
+	page := p.Timeout().page
+	if ele != nil {
+		if err := ele.element.ScrollIntoView(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	restore, err := p.prepareScreenshot(o)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer restore()
+
+	if o.OmitBackground {
+		err := proto.EmulationSetDefaultBackgroundColorOverride{Color: &proto.DOMRGBA{}}.Call(page)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() {
+			_ = proto.EmulationSetDefaultBackgroundColorOverride{}.Call(page)
+		}()
+	}
+
+	req := proto.PageCaptureScreenshot{Format: o.Format, Clip: o.Clip}
+	if o.Quality > 0 && o.Format != proto.PageCaptureScreenshotFormatPng {
+		req.Quality = gson.Int(o.Quality)
+	}
+
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(page)
+	if err != nil {
+		return nil, nil, err
+	}
+	viewport := metrics.CSSVisualViewport
+
+	switch {
+	case req.Clip != nil:
+		req.CaptureBeyondViewport = true
+	case ele != nil:
+		shape, err := ele.element.Shape()
+		if err != nil {
+			return nil, nil, err
+		}
+		box := shape.Box()
+		req.Clip = &proto.PageViewport{
+			X:      box.X + viewport.PageX,
+			Y:      box.Y + viewport.PageY,
+			Width:  box.Width,
+			Height: box.Height,
+		}
+		req.CaptureBeyondViewport = true
+	case o.FullPage:
+		size := metrics.CSSContentSize
+		req.Clip = &proto.PageViewport{Width: size.Width, Height: size.Height}
+		req.CaptureBeyondViewport = true
+	case o.Scale != 1:
+		req.Clip = &proto.PageViewport{
+			X:      viewport.PageX,
+			Y:      viewport.PageY,
+			Width:  viewport.ClientWidth,
+			Height: viewport.ClientHeight,
+		}
+	}
+
+	region := &proto.PageViewport{
+		X:      viewport.PageX,
+		Y:      viewport.PageY,
+		Width:  viewport.ClientWidth,
+		Height: viewport.ClientHeight,
+		Scale:  1,
+	}
+	if req.Clip != nil {
+		clip := *req.Clip
+		if clip.Scale == 0 {
+			clip.Scale = o.Scale
+		}
+		req.Clip, region = &clip, &clip
+	}
+
+	res, err := req.Call(page)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.Data, region, nil
+}
+
+// screenshot 截图并返回截取区域，iframe 页面截取框架所在区域
+func (p *Page) screenshot(opts ...func(o *ScreenshotOptions)) ([]byte, *proto.PageViewport, error) {
+	if p.owner != nil {
+		// 遮罩及隐藏的元素在框架页面中查找
+		restore, err := p.prepareScreenshot(screenshotOptions(opts))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer restore()
+
+		return p.owner.page.capture(p.owner, append(opts, func(o *ScreenshotOptions) {
+			o.Mask, o.Hide = nil, nil
+		})...)
+	}
+	return p.capture(nil, opts...)
+}
+
+// ScreenshotBytes 截图并返回图片数据，iframe 页面截取框架所在区域
+func (p *Page) ScreenshotBytes(opts ...func(o *ScreenshotOptions)) ([]byte, error) {
+	b, _, err := p.screenshot(opts...)
+	return b, err
+}
+
+// ScreenshotTo 截图并写入 w
+func (p *Page) ScreenshotTo(w io.Writer, opts ...func(o *ScreenshotOptions)) error {
+	b, err := p.ScreenshotBytes(opts...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Screenshot 截图，iframe 页面截取框架所在区域
+func (p *Page) Screenshot(file string, opts ...func(o *ScreenshotOptions)) error {
+	b, err := p.ScreenshotBytes(screenshotFile(file, opts)...)
+	if err != nil {
+		return err
+	}
+
+	return zfile.WriteFile(zfile.RealPath(file), b)
+}
+
+// ScreenshotFullPage 截图全屏，iframe 页面截取框架所在区域
+func (p *Page) ScreenshotFullPage(file string, opts ...func(o *ScreenshotOptions)) error {
+	return p.Screenshot(file, append(opts, func(o *ScreenshotOptions) {
+		o.FullPage = true
+	})...)
+}
+
+// ScreenshotBytes 截图元素并返回图片数据
+func (ele *Element) ScreenshotBytes(opts ...func(o *ScreenshotOptions)) ([]byte, error) {
+	b, _, err := ele.page.capture(ele, opts...)
+	return b, err
+}
+
+// ScreenshotTo 截图元素并写入 w
+func (ele *Element) ScreenshotTo(w io.Writer, opts ...func(o *ScreenshotOptions)) error {
+	b, err := ele.ScreenshotBytes(opts...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Screenshot 截图元素
+func (ele *Element) Screenshot(file string, opts ...func(o *ScreenshotOptions)) error {
+	b, err := ele.ScreenshotBytes(screenshotFile(file, opts)...)
+	if err != nil {
+		return err
+	}
+
+	return zfile.WriteFile(zfile.RealPath(file), b)
+}
@@ -0,0 +1,30 @@
+package browser
+
+import (
+	"image"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo"
+)
+
+func TestScreenshotFormat(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tt.Equal(proto.PageCaptureScreenshotFormatPng, screenshotFormat("a.png"))
+	tt.Equal(proto.PageCaptureScreenshotFormatPng, screenshotFormat("a"))
+	tt.Equal(proto.PageCaptureScreenshotFormatJpeg, screenshotFormat("a.JPG"))
+	tt.Equal(proto.PageCaptureScreenshotFormatWebp, screenshotFormat("tmp/a.webp"))
+}
+
+func TestIgnoreRegions(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	region := &proto.PageViewport{X: 0, Y: 100, Width: 400, Height: 300}
+	regions := ignoreRegions([]proto.DOMRect{
+		{X: 10, Y: 110, Width: 20.5, Height: 10},
+		{X: 390, Y: 390, Width: 50, Height: 50},
+		{X: 0, Y: 0, Width: 50, Height: 50},
+	}, region, image.Pt(800, 600))
+	tt.Equal([]image.Rectangle{image.Rect(20, 20, 61, 40), image.Rect(780, 580, 800, 600)}, regions)
+}
@@ -0,0 +1,497 @@
+package browser
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// PiercingPrefix 穿透所有 shadow DOM 的选择器前缀，例如 deep=button.submit
+const PiercingPrefix = "deep="
+
+// PiercingSeparator 进入宿主元素 shadow DOM 的分隔符，例如 my-app >>> button.submit
+const PiercingSeparator = ">>>"
+
+// ChainSeparator 链式选择器分隔符，后一段在前一段匹配的元素内查找，
+// 另支持 nth=0、has-text="文本"、has="选择器" 对前一段的结果进行筛选，例如 .item >> has-text="Go" >> nth=-1
+const ChainSeparator = ">>"
+
+// builtinSelectorEngines 内置选择器引擎，使用方式为 名称=内容，例如 text=保存、role=button[name="Save"]
+var builtinSelectorEngines = map[string]string{
+	"css":   `(root, body) => root.querySelectorAll(body)`,
+	"deep":  `(root, body, h) => h.scopes(root).flatMap(s => [...s.querySelectorAll(body)])`,
+	"xpath": jsXPathEngine,
+	"text":  jsTextEngine,
+	"role":  jsRoleEngine,
+	"label": jsLabelEngine,
+	"placeholder": `(root, body, h) => h.elements(root).filter(el => el.hasAttribute("placeholder") &&
+		h.match(el.getAttribute("placeholder"), body))`,
+	"testid": `(root, body, h) => h.elements(root).filter(el => el.getAttribute("data-testid") === h.unquote(body))`,
+}
+
+var selectorEngines = struct {
+	engines map[string]string
+	mu      sync.RWMutex
+}{engines: map[string]string{}}
+
+var selectorEngineName = regexp.MustCompile(`^[a-zA-Z][\w-]*$`)
+
+// RegisterSelectorEngine 注册自定义选择器引擎，script 为 JS 函数 (root, body, helper) => Element | Element[] | NodeList，
+// root 为查询范围，body 为前缀之后的内容，helper 提供 scopes、elements、match、text 等辅助方法
+func RegisterSelectorEngine(name, script string) error {
+	if !selectorEngineName.MatchString(name) {
+		return errors.New("invalid selector engine name: " + name)
+	}
+	if _, ok := builtinSelectorEngines[name]; ok {
+		return errors.New("selector engine is built-in: " + name)
+	}
+	if strings.TrimSpace(script) == "" {
+		return errors.New("selector engine script is empty")
+	}
+
+	selectorEngines.mu.Lock()
+	selectorEngines.engines[name] = script
+	selectorEngines.mu.Unlock()
+	return nil
+}
+
+// UnregisterSelectorEngine 移除自定义选择器引擎
+func UnregisterSelectorEngine(name string) {
+	selectorEngines.mu.Lock()
+	delete(selectorEngines.engines, name)
+	selectorEngines.mu.Unlock()
+}
+
+func selectorEngine(name string) (string, bool) {
+	if script, ok := builtinSelectorEngines[name]; ok {
+		return script, true
+	}
+
+	selectorEngines.mu.RLock()
+	script, ok := selectorEngines.engines[name]
+	selectorEngines.mu.RUnlock()
+	return script, ok
+}
+
+// isScriptSelector 是否需要通过脚本查询，即使用了选择器引擎、链式选择器或穿透 shadow DOM
+func isScriptSelector(selector string) bool {
+	if strings.Contains(selector, ChainSeparator) {
+		return true
+	}
+
+	name, _, ok := strings.Cut(selector, "=")
+	if !ok || name == "css" {
+		return false
+	}
+
+	_, ok = selectorEngine(name)
+	return ok
+}
+
+var selectorEngineRef = regexp.MustCompile(`([a-zA-Z][\w-]*)=`)
+
+// selectorScript 生成查询脚本，选择器中用到的引擎会被内联到脚本中
+func selectorScript(selector string) string {
+	names := map[string]struct{}{"css": {}}
+	for _, m := range selectorEngineRef.FindAllStringSubmatch(selector, -1) {
+		if _, ok := selectorEngine(m[1]); ok {
+			names[m[1]] = struct{}{}
+		}
+	}
+
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	var engines strings.Builder
+	for _, name := range keys {
+		script, _ := selectorEngine(name)
+		engines.WriteString("\t\t\"" + name + "\": (" + script + "),\n")
+	}
+
+	return strings.Replace(jsQuerySelector, "/* engines */", engines.String(), 1)
+}
+
+var jsQuerySelector = `(selector, all, ...closed) => {
+	const hosts = new Map(closed.filter(Boolean).map(r => [r.host, r]));
+	const shadow = el => el.shadowRoot || hosts.get(el) || null;
+	const root = this && this.nodeType ? this : document;
+	const normalize = s => (s || "").replace(/\s+/g, " ").trim();
+	const unquote = s => {
+		s = s.trim();
+		return /^(".*"|'.*')$/.test(s) ? s.slice(1, -1) : s;
+	};
+	const h = {
+		shadow, normalize, unquote,
+		scopes(node) {
+			const list = [node];
+			if (node.nodeType === 1 && shadow(node)) list.push(shadow(node));
+			for (let i = 0; i < list.length; i++) {
+				const walker = document.createTreeWalker(list[i], NodeFilter.SHOW_ELEMENT);
+				for (let n = walker.nextNode(); n; n = walker.nextNode()) {
+					const s = shadow(n);
+					if (s) list.push(s);
+				}
+			}
+			return list;
+		},
+		elements(node) {
+			return h.scopes(node).flatMap(s => [...s.querySelectorAll("*")]);
+		},
+		text(el) {
+			if (el.tagName === "INPUT" && /^(button|submit|reset)$/i.test(el.type)) return normalize(el.value);
+			const s = shadow(el);
+			return normalize((s ? s.textContent + " " : "") + (el.innerText !== undefined && el.isConnected ? el.innerText : el.textContent));
+		},
+		match(value, body) {
+			value = normalize(value);
+			body = body.trim();
+			const re = body.match(/^\/(.*)\/([a-z]*)$/s);
+			if (re) return new RegExp(re[1], re[2]).test(value);
+			if (/^(".*"|'.*')$/.test(body)) return value === normalize(body.slice(1, -1));
+			return value.toLowerCase().includes(normalize(body).toLowerCase());
+		},
+		visible(el) {
+			if (el.closest("[aria-hidden=true]")) return false;
+			const style = getComputedStyle(el);
+			return style.visibility !== "hidden" && el.getClientRects().length > 0;
+		}
+	};
+	const engines = {
+/* engines */	};
+	const query = (scope, part) => {
+		const m = part.match(/^([a-zA-Z][\w-]*)=([\s\S]*)$/);
+		const engine = m && engines[m[1]];
+		const found = engine ? engine(scope, m[2], h) : scope.querySelectorAll(part);
+		if (!found) return [];
+		return found.nodeType ? [found] : Array.from(found).filter(n => n && n.nodeType === 1);
+	};
+	const split = s => {
+		const parts = [];
+		let quote = "", start = 0, op = "";
+		for (let i = 0; i < s.length; i++) {
+			const c = s[i];
+			if (quote) {
+				if (c === "\\") i++;
+				else if (c === quote) quote = "";
+			} else if ((c === '"' || c === "'") && /[=\[(\s]/.test(s[i - 1] || "")) {
+				quote = c;
+			} else if (c === ">" && s[i + 1] === ">") {
+				const next = s[i + 2] === ">" ? ">>>" : ">>";
+				parts.push({ op, text: s.slice(start, i).trim() });
+				op = next;
+				i += next.length - 1;
+				start = i + 1;
+			}
+		}
+		parts.push({ op, text: s.slice(start).trim() });
+		return parts;
+	};
+	const run = (root, selector) => {
+		let matched = [root];
+		for (const { op, text } of split(selector)) {
+			const m = text.match(/^(nth|has-text|has)=([\s\S]*)$/);
+			if (m) {
+				const value = /^"/.test(m[2]) ? JSON.parse(m[2]) : m[2];
+				if (m[1] === "nth") {
+					const i = parseInt(value, 10);
+					const el = i < 0 ? matched[matched.length + i] : matched[i];
+					matched = el && el !== root ? [el] : [];
+				} else if (m[1] === "has-text") {
+					matched = matched.filter(el => el !== root && h.text(el).toLowerCase().includes(h.normalize(value).toLowerCase()));
+				} else {
+					matched = matched.filter(el => el !== root && run(el, value).length > 0);
+				}
+				continue;
+			}
+
+			const next = new Set();
+			for (const el of matched) {
+				query(op === "` + PiercingSeparator + `" && el !== root ? (shadow(el) || el) : el, text).forEach(n => next.add(n));
+			}
+			matched = [...next];
+		}
+		return matched.filter(el => el !== root);
+	};
+
+	const matched = run(root, selector);
+	return all ? matched : (matched[0] || null);
+}`
+
+var jsXPathEngine = `(root, body) => {
+	const doc = root.ownerDocument || root;
+	const res = doc.evaluate(body, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+	const list = [];
+	for (let i = 0; i < res.snapshotLength; i++) list.push(res.snapshotItem(i));
+	return list;
+}`
+
+var jsTextEngine = `(root, body, h) => {
+	const skip = new Set(["SCRIPT", "STYLE", "NOSCRIPT", "TEMPLATE", "HEAD", "TITLE"]);
+	const deepest = matched => {
+		const set = new Set(matched);
+		return matched.filter(el => {
+			const s = h.shadow(el);
+			return ![...el.children, ...(s ? s.children : [])].some(c => set.has(c));
+		});
+	};
+
+	const leaves = new Set();
+	for (const scope of h.scopes(root)) {
+		const walker = document.createTreeWalker(scope, NodeFilter.SHOW_ELEMENT | NodeFilter.SHOW_TEXT);
+		for (let n = walker.nextNode(); n; n = walker.nextNode()) {
+			if (n.nodeType === 1) {
+				if (n.tagName === "INPUT" && /^(button|submit|reset)$/i.test(n.type)) leaves.add(n);
+				continue;
+			}
+			const el = n.parentElement || n.parentNode.host;
+			if (el && el !== root && !skip.has(el.tagName) && h.match(n.data, body)) leaves.add(el);
+		}
+	}
+	const matched = [...leaves].filter(el => h.match(h.text(el), body));
+	if (matched.length) return deepest(matched);
+
+	return deepest(h.elements(root).filter(el => !skip.has(el.tagName) && h.match(h.text(el), body)));
+}`
+
+var jsLabelEngine = `(root, body, h) => {
+	const found = new Set();
+	for (const el of h.elements(root)) {
+		if (el.tagName === "LABEL" && h.match(h.text(el), body)) {
+			const control = el.control || el.querySelector("input, select, textarea, button");
+			if (control) found.add(control);
+		} else if (el.hasAttribute("aria-label") && h.match(el.getAttribute("aria-label"), body)) {
+			found.add(el);
+		} else if (el.hasAttribute("aria-labelledby")) {
+			const text = el.getAttribute("aria-labelledby").split(/\s+/)
+				.map(id => el.getRootNode().getElementById ? el.getRootNode().getElementById(id) : document.getElementById(id))
+				.filter(Boolean).map(n => h.text(n)).join(" ");
+			if (h.match(text, body)) found.add(el);
+		}
+	}
+	return [...found];
+}`
+
+var jsRoleEngine = `(root, body, h) => {
+	const m = body.match(/^([\w-]+)([\s\S]*)$/);
+	if (!m) return [];
+	const want = m[1].toLowerCase(), filters = [];
+	const attr = /\[\s*([\w-]+)\s*(?:=\s*("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\/(?:[^\/\\]|\\.)*\/[a-z]*|[^\]\s]+)\s*(s|i)?)?\s*\]/g;
+	for (let a; (a = attr.exec(m[2]));) filters.push({ name: a[1].toLowerCase(), value: a[2], exact: a[3] === "s" });
+
+	const inputRoles = { button: "button", submit: "button", reset: "button", image: "button", checkbox: "checkbox",
+		radio: "radio", range: "slider", number: "spinbutton", search: "searchbox" };
+	const tagRoles = { A: "link", AREA: "link", BUTTON: "button", TEXTAREA: "textbox", IMG: "img", UL: "list", OL: "list",
+		LI: "listitem", NAV: "navigation", MAIN: "main", ASIDE: "complementary", FORM: "form", TABLE: "table",
+		TR: "row", TD: "cell", TH: "columnheader", DIALOG: "dialog", OPTION: "option", PROGRESS: "progressbar",
+		ARTICLE: "article", HR: "separator", FIELDSET: "group", DETAILS: "group", SUMMARY: "button", OUTPUT: "status",
+		H1: "heading", H2: "heading", H3: "heading", H4: "heading", H5: "heading", H6: "heading",
+		HEADER: "banner", FOOTER: "contentinfo", SECTION: "region", DL: "list", DT: "term", DD: "definition" };
+	const fromContent = new Set(["button", "link", "heading", "cell", "columnheader", "rowheader", "option", "listitem",
+		"tab", "menuitem", "menuitemcheckbox", "menuitemradio", "checkbox", "radio", "switch", "treeitem", "tooltip", "row", "term"]);
+
+	const role = el => {
+		const explicit = (el.getAttribute("role") || "").trim().split(/\s+/)[0];
+		if (explicit) return explicit.toLowerCase();
+		if (el.tagName === "INPUT") {
+			const type = (el.getAttribute("type") || "text").toLowerCase();
+			if (type === "hidden") return "";
+			if (inputRoles[type]) return inputRoles[type];
+			return el.hasAttribute("list") ? "combobox" : "textbox";
+		}
+		if (el.tagName === "SELECT") return el.multiple || el.size > 1 ? "listbox" : "combobox";
+		if ((el.tagName === "A" || el.tagName === "AREA") && !el.hasAttribute("href")) return "";
+		if (el.tagName === "IMG" && el.getAttribute("alt") === "") return "presentation";
+		if (el.tagName === "SECTION" && !el.hasAttribute("aria-label") && !el.hasAttribute("aria-labelledby")) return "";
+		if ((el.tagName === "HEADER" || el.tagName === "FOOTER") && el.closest("article, aside, main, nav, section") !== null) return "";
+		return tagRoles[el.tagName] || "";
+	};
+	const name = (el, r) => {
+		const by = el.getAttribute("aria-labelledby");
+		if (by) {
+			const rootNode = el.getRootNode();
+			const text = by.split(/\s+/).map(id => (rootNode.getElementById ? rootNode.getElementById(id) : document.getElementById(id)))
+				.filter(Boolean).map(n => h.text(n)).join(" ");
+			if (text) return text;
+		}
+		const label = el.getAttribute("aria-label");
+		if (label && label.trim()) return h.normalize(label);
+		if (el.labels && el.labels.length) return [...el.labels].map(l => h.text(l)).join(" ");
+		if (el.tagName === "IMG" || (el.tagName === "INPUT" && el.type === "image")) return h.normalize(el.getAttribute("alt"));
+		if (el.tagName === "INPUT" && /^(button|submit|reset)$/i.test(el.type)) return h.normalize(el.value || el.type);
+		if (fromContent.has(r)) {
+			const text = h.text(el);
+			if (text) return text;
+		}
+		if (el.tagName === "FIELDSET") {
+			const legend = el.querySelector("legend");
+			if (legend) return h.text(legend);
+		}
+		return h.normalize(el.getAttribute("title") || el.getAttribute("placeholder"));
+	};
+	const state = (el, key) => {
+		const aria = el.getAttribute("aria-" + key);
+		if (aria !== null) return aria;
+		switch (key) {
+		case "checked": return el.indeterminate ? "mixed" : String(!!el.checked);
+		case "disabled": return String(!!el.disabled);
+		case "selected": return String(!!el.selected);
+		case "expanded": return el.tagName === "DETAILS" ? String(el.open) : null;
+		case "level": {
+			const l = /^H([1-6])$/.exec(el.tagName);
+			return l ? l[1] : null;
+		}
+		}
+		return null;
+	};
+
+	return h.elements(root).filter(el => {
+		const r = role(el);
+		if (r !== want) return false;
+		let hidden = false;
+		for (const f of filters) {
+			if (f.name === "include-hidden") {
+				hidden = f.value === undefined || h.unquote(f.value) !== "false";
+				continue;
+			}
+			if (f.name === "name") {
+				if (f.value === undefined) continue;
+				const n = name(el, r);
+				if (f.exact) {
+					if (n !== h.unquote(f.value)) return false;
+				} else if (!h.match(n, f.value.startsWith("/") ? f.value : h.unquote(f.value))) {
+					return false;
+				}
+				continue;
+			}
+			const v = state(el, f.name);
+			if (f.value === undefined ? v !== "true" : v !== h.unquote(f.value)) return false;
+		}
+		return hidden || h.visible(el);
+	});
+}`
+
+// selectorEval 生成选择器查询脚本，使用 deep= 或 >>> 时 closed shadow root 通过 CDP 获取后传入
+func selectorEval(p *rod.Page, scope *rod.Element, selector string, all bool) *rod.EvalOptions {
+	args := []interface{}{selector, all}
+	if piercesShadow(selector) {
+		args = append(args, closedShadowRoots(p, scope)...)
+	}
+	opts := rod.Eval(selectorScript(selector), args...)
+	if scope != nil {
+		opts = opts.This(scope.Object)
+	}
+	return opts
+}
+
+// piercesShadow 选择器是否显式穿透 shadow DOM，只有这时才需要遍历 DOM 查找 closed shadow root
+func piercesShadow(selector string) bool {
+	if strings.Contains(selector, PiercingSeparator) {
+		return true
+	}
+	for _, m := range selectorEngineRef.FindAllStringSubmatch(selector, -1) {
+		if m[1]+"=" == PiercingPrefix {
+			return true
+		}
+	}
+	return false
+}
+
+func closedShadowRoots(p *rod.Page, scope *rod.Element) []interface{} {
+	var err error
+	if scope == nil {
+		scope, err = p.Sleeper(rod.NotFoundSleeper).ElementByJS(rod.Eval(`() => document.documentElement`))
+		if err != nil {
+			return nil
+		}
+	}
+
+	node, err := scope.Describe(-1, true)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		roots []interface{}
+		walk  func(n *proto.DOMNode)
+	)
+	walk = func(n *proto.DOMNode) {
+		for _, s := range n.ShadowRoots {
+			if s.ShadowRootType == proto.DOMShadowRootTypeClosed {
+				if root, err := scope.Page().ElementFromNode(&proto.DOMNode{BackendNodeID: s.BackendNodeID}); err == nil {
+					roots = append(roots, root.Object)
+				}
+			}
+			walk(s)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(node)
+
+	return roots
+}
+
+func queryElement(p *rod.Page, scope *rod.Element, selector string) (*rod.Element, error) {
+	selector = strings.TrimPrefix(selector, "css=")
+	if !isScriptSelector(selector) {
+		if scope != nil {
+			return scope.Element(selector)
+		}
+		return p.Element(selector)
+	}
+
+	if scope != nil {
+		return scope.ElementByJS(selectorEval(p, scope, selector, false))
+	}
+	return p.ElementByJS(selectorEval(p, nil, selector, false))
+}
+
+func queryElements(p *rod.Page, scope *rod.Element, selector string) (rod.Elements, error) {
+	selector = strings.TrimPrefix(selector, "css=")
+	if !isScriptSelector(selector) {
+		if scope != nil {
+			return scope.Elements(selector)
+		}
+		return p.Elements(selector)
+	}
+
+	if scope != nil {
+		return scope.ElementsByJS(selectorEval(p, scope, selector, true))
+	}
+	return p.ElementsByJS(selectorEval(p, nil, selector, true))
+}
+
+func hasElement(p *rod.Page, scope *rod.Element, selector string) (bool, *rod.Element) {
+	has, ele, _ := lookupElement(p, scope, selector)
+	return has, ele
+}
+
+// lookupElement 立即查找元素，未找到时 has 为 false，查询失败时返回错误
+func lookupElement(p *rod.Page, scope *rod.Element, selector string) (has bool, ele *rod.Element, err error) {
+	selector = strings.TrimPrefix(selector, "css=")
+	if !isScriptSelector(selector) {
+		if scope != nil {
+			return scope.Has(selector)
+		}
+		return p.Has(selector)
+	}
+
+	if scope != nil {
+		ele, err = scope.ElementByJS(selectorEval(p, scope, selector, false))
+	} else {
+		ele, err = p.Sleeper(rod.NotFoundSleeper).ElementByJS(selectorEval(p, nil, selector, false))
+	}
+	if errors.Is(err, &rod.ElementNotFoundError{}) {
+		return false, nil, nil
+	}
+	return err == nil, ele, err
+}
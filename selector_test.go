@@ -0,0 +1,53 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestSelectorEngine(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	tt.EqualFalse(isScriptSelector("#main .item"))
+	tt.EqualFalse(isScriptSelector(`a[href="a=b"]`))
+	tt.EqualFalse(isScriptSelector("css=div"))
+	tt.EqualTrue(isScriptSelector("my-app >>> button"))
+	tt.EqualTrue(isScriptSelector(".item >> nth=0"))
+	tt.EqualTrue(isScriptSelector("deep=button"))
+	tt.EqualTrue(isScriptSelector(`role=button[name="Save"]`))
+	tt.EqualTrue(isScriptSelector("xpath=//div"))
+
+	tt.EqualTrue(piercesShadow("my-app >>> button"))
+	tt.EqualTrue(piercesShadow("deep=button"))
+	tt.EqualTrue(piercesShadow(".item >> deep=button"))
+	tt.EqualFalse(piercesShadow("text=Save"))
+	tt.EqualFalse(piercesShadow(".item >> nth=0"))
+
+	tt.EqualTrue(RegisterSelectorEngine("text", "() => null") != nil)
+	tt.EqualTrue(RegisterSelectorEngine("1abc", "() => null") != nil)
+	tt.EqualFalse(isScriptSelector("data=id"))
+	tt.NoError(RegisterSelectorEngine("data", `(root, body) => root.querySelectorAll("[data-id='" + body + "']")`))
+	tt.EqualTrue(isScriptSelector("data=id"))
+
+	script := selectorScript("data=id >>> text=Save")
+	tt.EqualTrue(strings.Contains(script, `"data": (`))
+	tt.EqualTrue(strings.Contains(script, `"text": (`))
+	tt.EqualFalse(strings.Contains(script, `"role": (`))
+
+	UnregisterSelectorEngine("data")
+	tt.EqualFalse(isScriptSelector("data=id"))
+}
+
+func TestLocatorSelector(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	page := &Page{}
+	l := page.Locator(".item").Filter(func(o *LocatorFilter) {
+		o.HasText = "Go >> Rod"
+		o.Has = page.Locator("span >> text=New")
+	}).Last()
+	tt.Equal(`.item >> has-text="Go \u003e\u003e Rod" >> has="span \u003e\u003e text=New" >> nth=-1`, l.String())
+	tt.Equal(".item >> nth=2 >> a", page.Locator(".item").Nth(2).Locator("a").String())
+}
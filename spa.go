@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// SpaOptions 单页应用路由变化等待选项
+type SpaOptions struct {
+	// Timeout 等待超时时间，默认为页面超时时间
+	Timeout time.Duration
+	// DOMStable 大于 0 时在路由变化后等待 DOM 稳定，值为 WaitDOMStable 的 diff
+	DOMStable float64
+	// NetworkIdle 大于 0 时在路由变化后等待网络空闲的时长
+	NetworkIdle time.Duration
+}
+
+// WaitSpaNavigation 执行 fn 并等待单页应用的路由变化，
+// 包括 history.pushState、replaceState、popstate、hash 变化等同文档导航
+func (page *Page) WaitSpaNavigation(fn func() error, opts ...func(o *SpaOptions)) error {
+	o := zutil.Optional(SpaOptions{}, opts...)
+	if o.Timeout == 0 {
+		o.Timeout = page.GetTimeout()
+	}
+
+	p := page.Timeout(o.Timeout).page
+	info, err := p.Info()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(p.GetContext())
+	defer cancel()
+
+	var changed int32
+	frameID := p.FrameID
+	wait := p.Context(ctx).EachEvent(func(e *proto.PageNavigatedWithinDocument) bool {
+		if e.FrameID != frameID {
+			return false
+		}
+		atomic.StoreInt32(&changed, 1)
+		return true
+	}, func(e *proto.PageFrameNavigated) bool {
+		if e.Frame.ID != frameID {
+			return false
+		}
+		atomic.StoreInt32(&changed, 1)
+		return true
+	})
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	if err = fn(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for atomic.LoadInt32(&changed) == 0 {
+		select {
+		case <-done:
+			if atomic.LoadInt32(&changed) == 0 {
+				return context.DeadlineExceeded
+			}
+		case <-ticker.C:
+			if current, err := p.Info(); err == nil && current.URL != info.URL {
+				atomic.StoreInt32(&changed, 1)
+			}
+		}
+	}
+	cancel()
+
+	if o.NetworkIdle > 0 {
+		page.Timeout(o.Timeout).page.WaitRequestIdle(o.NetworkIdle, nil, nil, nil)()
+	}
+	if o.DOMStable > 0 {
+		return page.Timeout(o.Timeout).WaitDOMStable(o.DOMStable)
+	}
+
+	return nil
+}
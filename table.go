@@ -0,0 +1,251 @@
+package browser
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// TableOptions 表格提取选项
+type TableOptions struct {
+	// HeaderRows 表头行数，0 为自动识别（thead 中的行或只包含 th 的前几行），负数表示没有表头
+	HeaderRows int
+	// HeaderSeparator 多行表头合并时的分隔符，默认为空格
+	HeaderSeparator string
+	// Attrs 需要提取的属性，从单元格或其第一个包含该属性的子元素中读取
+	Attrs []string
+	// Value 行数据中单元格的取值，text（默认）、href 或 Attrs 中的属性名，值为空时使用文本
+	Value string
+}
+
+// TableCell 表格单元格，跨行跨列的单元格会填充到其覆盖的每个位置
+type TableCell struct {
+	Attrs map[string]string `json:"attrs,omitempty"`
+	Text  string            `json:"text"`
+	Href  string            `json:"href,omitempty"`
+}
+
+// Table 表格数据
+type Table struct {
+	// Header 表头，多行表头按列合并
+	Header []string
+	// Cells 数据行的单元格
+	Cells [][]TableCell
+	value string
+}
+
+var jsTable = `(attrs) => {
+	const selector = "table, [role=grid], [role=table], [role=treegrid]";
+	const table = this.matches(selector) ? this : this.querySelector(selector);
+	if (!table) throw new Error("table not found");
+
+	const aria = table.tagName !== "TABLE";
+	const rows = aria ? Array.from(table.querySelectorAll("[role=row]")) : Array.from(table.rows);
+	const cellsOf = (r) => aria
+		? Array.from(r.querySelectorAll("[role=cell], [role=gridcell], [role=columnheader], [role=rowheader]"))
+		: Array.from(r.cells);
+	const isHead = (c) => aria ? c.getAttribute("role") === "columnheader" : c.tagName === "TH";
+	const span = (c, name) => parseInt(aria ? c.getAttribute("aria-" + name) : c[name === "colspan" ? "colSpan" : "rowSpan"], 10);
+
+	const grid = [], heads = [];
+	rows.forEach((r, i) => {
+		grid[i] = grid[i] || [];
+		const cells = cellsOf(r);
+		heads[i] = (!aria && r.parentElement.tagName === "THEAD") || (cells.length > 0 && cells.every(isHead));
+
+		let col = 0;
+		for (const c of cells) {
+			while (grid[i][col]) col++;
+			const colspan = Math.max(1, span(c, "colspan") || 1);
+			let rowspan = span(c, "rowspan");
+			rowspan = rowspan === 0 ? rows.length - i : Math.max(1, rowspan || 1);
+
+			const link = c.matches("a[href]") ? c : c.querySelector("a[href]");
+			const cell = { text: (c.innerText || c.textContent || "").replace(/\s+/g, " ").trim(), href: link ? link.href : "", attrs: {} };
+			for (const name of attrs) {
+				const el = c.hasAttribute(name) ? c : c.querySelector("[" + CSS.escape(name) + "]");
+				if (el) cell.attrs[name] = el.getAttribute(name);
+			}
+
+			for (let y = 0; y < rowspan && i + y < rows.length; y++) {
+				grid[i + y] = grid[i + y] || [];
+				for (let x = 0; x < colspan; x++) grid[i + y][col + x] = cell;
+			}
+			col += colspan;
+		}
+	});
+
+	const width = Math.max(0, ...grid.map((r) => r.length));
+	return {
+		heads,
+		grid: grid.map((r) => Array.from({ length: width }, (_, x) => r[x] || { text: "", href: "", attrs: {} })),
+	};
+}`
+
+// Table 提取表格数据，元素可以是 table、ARIA grid/table，或包含它们的容器
+func (e *Element) Table(opts ...func(o *TableOptions)) (*Table, error) {
+	o := zutil.Optional(TableOptions{HeaderSeparator: " "}, opts...)
+	if o.Attrs == nil {
+		o.Attrs = []string{}
+	}
+
+	res, err := e.element.Eval(jsTable, o.Attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Heads []bool        `json:"heads"`
+		Grid  [][]TableCell `json:"grid"`
+	}
+	if err = res.Value.Unmarshal(&data); err != nil {
+		return nil, err
+	}
+
+	headerRows := o.HeaderRows
+	if headerRows == 0 {
+		for headerRows < len(data.Heads) && data.Heads[headerRows] {
+			headerRows++
+		}
+	} else if headerRows < 0 {
+		headerRows = 0
+	}
+	if headerRows > len(data.Grid) {
+		headerRows = len(data.Grid)
+	}
+
+	return &Table{
+		Header: tableHeader(data.Grid[:headerRows], o.HeaderSeparator),
+		Cells:  data.Grid[headerRows:],
+		value:  o.Value,
+	}, nil
+}
+
+// tableHeader 按列合并多行表头，跨列单元格在同一列重复出现时只保留一次
+func tableHeader(rows [][]TableCell, sep string) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	header := make([]string, len(rows[0]))
+	for x := range header {
+		parts := make([]string, 0, len(rows))
+		for y := range rows {
+			if x >= len(rows[y]) {
+				continue
+			}
+			text := rows[y][x].Text
+			if text == "" || (len(parts) > 0 && parts[len(parts)-1] == text) {
+				continue
+			}
+			parts = append(parts, text)
+		}
+		header[x] = strings.Join(parts, sep)
+	}
+
+	return header
+}
+
+func (t *Table) cellValue(c TableCell) string {
+	switch t.value {
+	case "", "text":
+	case "href":
+		if c.Href != "" {
+			return c.Href
+		}
+	default:
+		if v := c.Attrs[t.value]; v != "" {
+			return v
+		}
+	}
+	return c.Text
+}
+
+// Rows 数据行
+func (t *Table) Rows() [][]string {
+	rows := make([][]string, 0, len(t.Cells))
+	for _, cells := range t.Cells {
+		row := make([]string, len(cells))
+		for i := range cells {
+			row[i] = t.cellValue(cells[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Keys 行数据的键，为空的表头使用 column 加列序号，重复的表头追加序号
+func (t *Table) Keys() []string {
+	width := len(t.Header)
+	for i := range t.Cells {
+		if len(t.Cells[i]) > width {
+			width = len(t.Cells[i])
+		}
+	}
+
+	keys := make([]string, width)
+	seen := make(map[string]int, width)
+	for i := range keys {
+		key := ""
+		if i < len(t.Header) {
+			key = t.Header[i]
+		}
+		if key == "" {
+			key = "column" + strconv.Itoa(i+1)
+		}
+		if n := seen[key]; n > 0 {
+			seen[key]++
+			key = key + "_" + strconv.Itoa(n+1)
+		} else {
+			seen[key] = 1
+		}
+		keys[i] = key
+	}
+
+	return keys
+}
+
+// Maps 以表头为键的数据行
+func (t *Table) Maps() []map[string]string {
+	keys := t.Keys()
+	rows := t.Rows()
+	maps := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		m := make(map[string]string, len(keys))
+		for i := range row {
+			m[keys[i]] = row[i]
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
+
+// WriteCSV 写入 CSV，有表头时第一行为表头
+func (t *Table) WriteCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	if len(t.Header) > 0 {
+		if err := c.Write(t.Keys()); err != nil {
+			return err
+		}
+	}
+	if err := c.WriteAll(t.Rows()); err != nil {
+		return err
+	}
+	return c.Error()
+}
+
+// WriteJSONL 每行写入一个以表头为键的 JSON 对象
+func (t *Table) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, m := range t.Maps() {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package browser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestTable(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	head := [][]TableCell{
+		{{Text: "Name"}, {Text: "2024"}, {Text: "2024"}, {Text: ""}},
+		{{Text: "Name"}, {Text: "Q1"}, {Text: "Q2"}, {Text: ""}},
+	}
+	table := &Table{
+		Header: tableHeader(head, " "),
+		Cells: [][]TableCell{
+			{{Text: "Go", Href: "https://go.dev"}, {Text: "1"}, {Text: "2"}, {Text: "x"}},
+			{{Text: "Rod"}, {Text: "3"}, {Text: "4"}, {Text: "y"}},
+		},
+		value: "href",
+	}
+	tt.Equal([]string{"Name", "2024 Q1", "2024 Q2", ""}, table.Header)
+	tt.Equal([]string{"Name", "2024 Q1", "2024 Q2", "column4"}, table.Keys())
+	tt.Equal("https://go.dev", table.Maps()[0]["Name"])
+	tt.Equal("Rod", table.Rows()[1][0])
+
+	var buf bytes.Buffer
+	tt.NoError(table.WriteCSV(&buf))
+	tt.Equal("Name,2024 Q1,2024 Q2,column4\nhttps://go.dev,1,2,x\nRod,3,4,y\n", buf.String())
+
+	buf.Reset()
+	tt.NoError(table.WriteJSONL(&buf))
+	tt.Equal(`{"2024 Q1":"3","2024 Q2":"4","Name":"Rod","column4":"y"}`, string(bytes.Split(buf.Bytes(), []byte("\n"))[1]))
+}
@@ -0,0 +1,92 @@
+package browser
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/zerror"
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// PageInfo 标签页信息
+type PageInfo struct {
+	TargetID proto.TargetTargetID
+	OpenerID proto.TargetTargetID
+	URL      string
+	Title    string
+	Attached bool
+}
+
+// Pages 获取浏览器中的所有标签页，包括通过 WSEndpoint 连接时手动打开的标签页
+func (b *Browser) Pages() ([]PageInfo, error) {
+	res, err := proto.TargetGetTargets{}.Call(b.Browser)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]PageInfo, 0, len(res.TargetInfos))
+	for _, v := range res.TargetInfos {
+		if v.Type != proto.TargetTargetInfoTypePage {
+			continue
+		}
+		if b.Browser.BrowserContextID != "" && v.BrowserContextID != b.Browser.BrowserContextID {
+			continue
+		}
+
+		pages = append(pages, PageInfo{
+			TargetID: v.TargetID,
+			OpenerID: v.OpenerID,
+			URL:      v.URL,
+			Title:    v.Title,
+			Attached: v.Attached,
+		})
+	}
+
+	return pages, nil
+}
+
+// FindPage 查找第一个满足条件的标签页并接管
+func (b *Browser) FindPage(fn func(info PageInfo) bool, opts ...func(o *PageOptions)) (*Page, error) {
+	pages, err := b.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range pages {
+		if fn(pages[i]) {
+			return b.AttachPage(pages[i].TargetID, opts...)
+		}
+	}
+
+	return nil, errors.New("page not found")
+}
+
+// AttachPage 接管已存在的标签页，会应用用户代理、隐身脚本、启动脚本及请求拦截等配置，
+// 脚本在下一次导航后生效，不再使用时需要调用 Release 或 Close
+func (b *Browser) AttachPage(targetID proto.TargetTargetID, opts ...func(o *PageOptions)) (*Page, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	page, err := b.Browser.PageFromTarget(targetID)
+	if err != nil {
+		return nil, zerror.With(err, "failed to attach the tab")
+	}
+
+	o := zutil.Optional(PageOptions{
+		Timeout: time.Second * 120,
+	}, opts...)
+
+	return b.setupPage(page, o)
+}
+
+// TargetID 获取标签页 ID
+func (page *Page) TargetID() proto.TargetTargetID {
+	return page.page.TargetID
+}
+
+// BringToFront 将标签页切换到前台
+func (page *Page) BringToFront() error {
+	return proto.PageBringToFront{}.Call(page.page)
+}
@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo"
+)
+
+func TestTabs(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `<html><head><title>tab</title></head><body></body></html>`)
+	}))
+	defer srv.Close()
+
+	b := newTestBrowser(t)
+	rp, err := b.Browser.Page(proto.TargetCreateTarget{URL: srv.URL + "/tab"})
+	tt.NoError(err)
+	tt.NoError(rp.WaitLoad())
+
+	pages, err := b.Pages()
+	tt.NoError(err)
+	found := false
+	for _, v := range pages {
+		if v.TargetID == rp.TargetID {
+			found = true
+			tt.Equal(srv.URL+"/tab", v.URL)
+		}
+	}
+	tt.EqualTrue(found)
+
+	p, err := b.FindPage(func(info PageInfo) bool {
+		return strings.HasSuffix(info.URL, "/tab")
+	})
+	tt.NoError(err)
+	tt.Equal(rp.TargetID, p.TargetID())
+	tt.NoError(p.BringToFront())
+
+	title, err := p.EvalJS(`() => document.title`)
+	tt.NoError(err)
+	tt.Equal("tab", title.Str())
+
+	_, err = b.FindPage(func(info PageInfo) bool {
+		return info.URL == "about:none"
+	})
+	tt.EqualTrue(err != nil)
+
+	tt.NoError(p.Close())
+}
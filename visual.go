@@ -0,0 +1,141 @@
+package browser
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/sohaha/zlsgo/ztype"
+	"github.com/sohaha/zlsgo/zutil"
+	"github.com/zlsgo/browser/visual"
+)
+
+// ExpectScreenshotOptions 视觉对比选项
+type ExpectScreenshotOptions struct {
+	// Screenshot 截图选项，截图固定为 png 格式
+	Screenshot func(o *ScreenshotOptions)
+	// Compare 对比选项，例如阈值、允许的差异比例
+	Compare func(o *visual.Options)
+	// Dir 基准截图目录，默认 testdata/screenshots
+	Dir string
+	// Ignore 对比时忽略的元素选择器，例如时间、广告
+	Ignore []string
+	// Update 用新截图覆盖基准截图，默认读取 visual.UpdateEnv 环境变量
+	Update bool
+	// Strict 基准截图不存在时返回 visual.ErrMissing
+	Strict bool
+}
+
+var jsClientRects = `(doc, ...elements) => {
+	const rects = [], x = doc ? scrollX : 0, y = doc ? scrollY : 0;
+	for (const el of elements) {
+		for (const r of el.getClientRects()) {
+			rects.push({ x: r.left + x, y: r.top + y, width: r.width, height: r.height });
+		}
+	}
+	return rects;
+}`
+
+// ExpectScreenshot 截图并与名为 name 的基准截图对比，
+// 不一致时在基准截图旁保存新截图及差异图并返回 visual.ErrMismatch
+func (p *Page) ExpectScreenshot(name string, opts ...func(o *ExpectScreenshotOptions)) (*visual.BaselineResult, error) {
+	o := zutil.Optional(ExpectScreenshotOptions{
+		Dir:    "testdata/screenshots",
+		Update: ztype.ToBool(os.Getenv(visual.UpdateEnv)),
+	}, opts...)
+
+	shotOpts := make([]func(o *ScreenshotOptions), 0, 2)
+	if o.Screenshot != nil {
+		shotOpts = append(shotOpts, o.Screenshot)
+	}
+	shotOpts = append(shotOpts, func(so *ScreenshotOptions) {
+		so.Format = proto.PageCaptureScreenshotFormatPng
+	})
+
+	data, region, err := p.screenshot(shotOpts...)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var ignore []image.Rectangle
+	if len(o.Ignore) > 0 {
+		rects, err := p.clientRects(o.Ignore)
+		if err != nil {
+			return nil, err
+		}
+		if p.owner != nil {
+			region = &proto.PageViewport{Width: region.Width, Height: region.Height}
+		}
+		ignore = ignoreRegions(rects, region, img.Bounds().Size())
+	}
+
+	compare := make([]func(o *visual.Options), 0, 2)
+	if o.Compare != nil {
+		compare = append(compare, o.Compare)
+	}
+	if len(ignore) > 0 {
+		compare = append(compare, func(vo *visual.Options) {
+			vo.Ignore = append(vo.Ignore, ignore...)
+		})
+	}
+
+	baseline := &visual.Baseline{Dir: o.Dir, Update: o.Update, Strict: o.Strict}
+	return baseline.Check(name, img, compare...)
+}
+
+// clientRects 获取元素的区域，iframe 页面为框架视口坐标，否则为页面坐标
+func (p *Page) clientRects(selectors []string) ([]proto.DOMRect, error) {
+	page := p.Timeout().page
+	args := []interface{}{p.owner == nil}
+	for _, s := range selectors {
+		es, err := queryElements(page, nil, s)
+		if err != nil {
+			return nil, err
+		}
+		for i := range es {
+			args = append(args, es[i].Object)
+		}
+	}
+	if len(args) == 1 {
+		return nil, nil
+	}
+
+	res, err := page.Eval(jsClientRects, args...)
+	if err != nil {
+		return nil, err
+	}
+	var rects []proto.DOMRect
+	err = res.Value.Unmarshal(&rects)
+	return rects, err
+}
+
+// ignoreRegions 将页面坐标转换为截图中的像素区域
+func ignoreRegions(rects []proto.DOMRect, region *proto.PageViewport, size image.Point) []image.Rectangle {
+	if region.Width <= 0 || region.Height <= 0 {
+		return nil
+	}
+
+	sx, sy := float64(size.X)/region.Width, float64(size.Y)/region.Height
+	bounds := image.Rect(0, 0, size.X, size.Y)
+	regions := make([]image.Rectangle, 0, len(rects))
+	for _, r := range rects {
+		rect := image.Rect(
+			int(math.Floor((r.X-region.X)*sx)),
+			int(math.Floor((r.Y-region.Y)*sy)),
+			int(math.Ceil((r.X+r.Width-region.X)*sx)),
+			int(math.Ceil((r.Y+r.Height-region.Y)*sy)),
+		).Intersect(bounds)
+		if !rect.Empty() {
+			regions = append(regions, rect)
+		}
+	}
+	return regions
+}
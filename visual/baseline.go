@@ -0,0 +1,143 @@
+package visual
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zfile"
+	"github.com/sohaha/zlsgo/ztype"
+)
+
+// UpdateEnv 环境变量为 true 或 1 时更新基准截图
+const UpdateEnv = "VISUAL_UPDATE"
+
+var (
+	// ErrMismatch 截图与基准截图不一致
+	ErrMismatch = errors.New("visual: screenshot does not match baseline")
+	// ErrMissing 基准截图不存在
+	ErrMissing = errors.New("visual: baseline not found")
+)
+
+// Baseline 基准截图目录
+type Baseline struct {
+	// Dir 基准截图所在目录
+	Dir string
+	// Update 为 true 时用新截图覆盖基准截图，默认读取 UpdateEnv 环境变量
+	Update bool
+	// Strict 为 true 时基准截图不存在返回 ErrMissing，否则保存为新的基准截图，CI 中建议开启
+	Strict bool
+}
+
+// BaselineResult 与基准截图的对比结果
+type BaselineResult struct {
+	*Result
+	// Baseline 基准截图路径
+	Baseline string `json:"baseline"`
+	// Actual 不一致时保存的新截图路径
+	Actual string `json:"actual,omitempty"`
+	// DiffFile 不一致时保存的差异图路径
+	DiffFile string `json:"diffFile,omitempty"`
+	// Created 基准截图不存在，已保存为新的基准截图
+	Created bool `json:"created"`
+	// Updated 处于更新模式，已覆盖基准截图
+	Updated bool `json:"updated"`
+}
+
+// NewBaseline 创建基准截图目录
+func NewBaseline(dir string) *Baseline {
+	return &Baseline{
+		Dir:    dir,
+		Update: ztype.ToBool(os.Getenv(UpdateEnv)),
+	}
+}
+
+// Path 基准截图路径，name 没有扩展名时使用 .png
+func (b *Baseline) Path(name string) string {
+	if filepath.Ext(name) == "" {
+		name += ".png"
+	}
+	return zfile.RealPath(filepath.Join(b.Dir, name))
+}
+
+func (b *Baseline) siblings(file string) (actual, diff string) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	return base + ".actual.png", base + ".diff.png"
+}
+
+// CheckBytes 与 Check 相同，actual 为编码后的图片数据
+func (b *Baseline) CheckBytes(name string, actual []byte, opts ...func(o *Options)) (*BaselineResult, error) {
+	img, _, err := image.Decode(bytes.NewReader(actual))
+	if err != nil {
+		return nil, err
+	}
+	return b.Check(name, img, opts...)
+}
+
+// Check 将 actual 与名为 name 的基准截图对比，
+// 不一致时在基准截图旁保存 .actual.png 及 .diff.png 并返回 ErrMismatch
+func (b *Baseline) Check(name string, actual image.Image, opts ...func(o *Options)) (*BaselineResult, error) {
+	file := b.Path(name)
+	actualFile, diffFile := b.siblings(file)
+	res := &BaselineResult{Baseline: file}
+
+	exist := zfile.FileExist(file)
+	if b.Update || !exist {
+		if !exist && !b.Update && b.Strict {
+			return nil, fmt.Errorf("%w: %s", ErrMissing, file)
+		}
+		if err := writePNG(file, actual); err != nil {
+			return nil, err
+		}
+		_ = os.Remove(actualFile)
+		_ = os.Remove(diffFile)
+
+		size := actual.Bounds().Size()
+		res.Result = &Result{Width: size.X, Height: size.Y, Match: true}
+		res.Created, res.Updated = !exist, exist
+		return res, nil
+	}
+
+	expected, err := readImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Result = Compare(expected, actual, opts...)
+	if res.Match {
+		_ = os.Remove(actualFile)
+		_ = os.Remove(diffFile)
+		return res, nil
+	}
+
+	if err = writePNG(actualFile, actual); err != nil {
+		return nil, err
+	}
+	if err = writePNG(diffFile, res.Diff); err != nil {
+		return nil, err
+	}
+	res.Actual, res.DiffFile = actualFile, diffFile
+	return res, fmt.Errorf("%w: %s, %s", ErrMismatch, name, res.Result)
+}
+
+func readImage(file string) (image.Image, error) {
+	data, err := zfile.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func writePNG(file string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	return zfile.WriteFile(file, buf.Bytes())
+}
@@ -0,0 +1,290 @@
+package visual
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/sohaha/zlsgo/zutil"
+)
+
+// Options 对比选项
+type Options struct {
+	// Ignore 忽略的区域，为截图中的像素坐标
+	Ignore []image.Rectangle
+	// DiffColor 差异图中差异像素的颜色，默认红色
+	DiffColor color.NRGBA
+	// AAColor 差异图中抗锯齿像素的颜色，默认黄色
+	AAColor color.NRGBA
+	// Threshold 单个像素的颜色差异阈值，0-1，越小越严格，默认 0.1
+	Threshold float64
+	// MaxDiffRatio 允许的差异像素比例，0-1，默认 0 即不允许任何差异
+	MaxDiffRatio float64
+	// Alpha 差异图中未变化像素的不透明度，0-1，默认 0.1
+	Alpha float64
+	// IncludeAA 为 true 时抗锯齿像素也计为差异
+	IncludeAA bool
+}
+
+// Result 对比结果
+type Result struct {
+	// Diff 差异图，差异像素为 DiffColor，抗锯齿像素为 AAColor
+	Diff *image.NRGBA `json:"-"`
+	// Width 对比的宽度，尺寸不一致时为两者中较大的
+	Width int `json:"width"`
+	// Height 对比的高度，尺寸不一致时为两者中较大的
+	Height int `json:"height"`
+	// DiffPixels 差异像素数
+	DiffPixels int `json:"diffPixels"`
+	// AAPixels 被识别为抗锯齿而忽略的像素数
+	AAPixels int `json:"aaPixels"`
+	// IgnoredPixels 忽略区域内的像素数
+	IgnoredPixels int `json:"ignoredPixels"`
+	// DiffRatio 差异像素占全部像素的比例
+	DiffRatio float64 `json:"diffRatio"`
+	// SizeMismatch 两张图片尺寸不一致
+	SizeMismatch bool `json:"sizeMismatch"`
+	// Match 差异在允许范围内
+	Match bool `json:"match"`
+}
+
+func (r *Result) String() string {
+	if r.SizeMismatch {
+		return fmt.Sprintf("size mismatch, %d of %d pixels (%.2f%%) differ",
+			r.DiffPixels, r.Width*r.Height, r.DiffRatio*100)
+	}
+	return fmt.Sprintf("%d of %d pixels (%.2f%%) differ",
+		r.DiffPixels, r.Width*r.Height, r.DiffRatio*100)
+}
+
+// maxYIQDelta YIQ 色彩空间中两种颜色的最大差值
+const maxYIQDelta = 35215
+
+// Compare 逐像素对比两张图片，算法参考 pixelmatch，
+// 尺寸不一致时超出部分全部计为差异且结果不匹配
+func Compare(expected, actual image.Image, opts ...func(o *Options)) *Result {
+	o := zutil.Optional(Options{
+		Threshold: 0.1,
+		Alpha:     0.1,
+		DiffColor: color.NRGBA{R: 255, A: 255},
+		AAColor:   color.NRGBA{R: 255, G: 255, A: 255},
+	}, opts...)
+
+	a, b := toNRGBA(expected), toNRGBA(actual)
+	aw, ah := a.Rect.Dx(), a.Rect.Dy()
+	bw, bh := b.Rect.Dx(), b.Rect.Dy()
+	r := &Result{
+		Width:        maxInt(aw, bw),
+		Height:       maxInt(ah, bh),
+		SizeMismatch: aw != bw || ah != bh,
+	}
+	r.Diff = image.NewNRGBA(image.Rect(0, 0, r.Width, r.Height))
+
+	maxDelta := maxYIQDelta * o.Threshold * o.Threshold
+	w, h := minInt(aw, bw), minInt(ah, bh)
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			if ignored(o.Ignore, x, y) {
+				r.IgnoredPixels++
+				if x < aw && y < ah {
+					setGray(r.Diff, x, y, a, o.Alpha)
+				}
+				continue
+			}
+			if x >= w || y >= h {
+				r.DiffPixels++
+				r.Diff.SetNRGBA(x, y, o.DiffColor)
+				continue
+			}
+
+			delta := colorDelta(a, b, x, y, x, y, false)
+			switch {
+			case math.Abs(delta) <= maxDelta:
+				setGray(r.Diff, x, y, a, o.Alpha)
+			case !o.IncludeAA && (antialiased(a, b, x, y) || antialiased(b, a, x, y)):
+				r.AAPixels++
+				r.Diff.SetNRGBA(x, y, o.AAColor)
+			default:
+				r.DiffPixels++
+				r.Diff.SetNRGBA(x, y, o.DiffColor)
+			}
+		}
+	}
+
+	if total := r.Width * r.Height; total > 0 {
+		r.DiffRatio = float64(r.DiffPixels) / float64(total)
+	}
+	r.Match = !r.SizeMismatch && r.DiffRatio <= o.MaxDiffRatio
+	return r
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) {
+		return n
+	}
+	b := img.Bounds()
+	n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(n, n.Rect, img, b.Min, draw.Src)
+	return n
+}
+
+func ignored(rects []image.Rectangle, x, y int) bool {
+	p := image.Pt(x, y)
+	for i := range rects {
+		if p.In(rects[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// blend 将颜色与白色背景混合
+func blend(c, a float64) float64 {
+	return 255 + (c-255)*a
+}
+
+func rgb2y(r, g, b float64) float64 {
+	return r*0.29889531 + g*0.58662247 + b*0.11448223
+}
+
+func rgb2i(r, g, b float64) float64 {
+	return r*0.59597799 - g*0.27417610 - b*0.32180189
+}
+
+func rgb2q(r, g, b float64) float64 {
+	return r*0.21147017 - g*0.52261711 + b*0.31114694
+}
+
+func pixel(img *image.NRGBA, x, y int) (r, g, b, a float64) {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+4 : i+4]
+	r, g, b, a = float64(p[0]), float64(p[1]), float64(p[2]), float64(p[3])/255
+	if a < 1 {
+		r, g, b = blend(r, a), blend(g, a), blend(b, a)
+	}
+	return
+}
+
+func samePixel(a, b *image.NRGBA, x1, y1, x2, y2 int) bool {
+	i, j := a.PixOffset(x1, y1), b.PixOffset(x2, y2)
+	return a.Pix[i] == b.Pix[j] && a.Pix[i+1] == b.Pix[j+1] &&
+		a.Pix[i+2] == b.Pix[j+2] && a.Pix[i+3] == b.Pix[j+3]
+}
+
+// colorDelta 计算两个像素在 YIQ 色彩空间中的差值，yOnly 时只计算亮度差，
+// 第一个像素更亮时返回负数
+func colorDelta(a, b *image.NRGBA, x1, y1, x2, y2 int, yOnly bool) float64 {
+	if samePixel(a, b, x1, y1, x2, y2) {
+		return 0
+	}
+
+	r1, g1, b1, _ := pixel(a, x1, y1)
+	r2, g2, b2, _ := pixel(b, x2, y2)
+	ya, yb := rgb2y(r1, g1, b1), rgb2y(r2, g2, b2)
+	y := ya - yb
+	if yOnly {
+		return y
+	}
+
+	i := rgb2i(r1, g1, b1) - rgb2i(r2, g2, b2)
+	q := rgb2q(r1, g1, b1) - rgb2q(r2, g2, b2)
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	if ya > yb {
+		return -delta
+	}
+	return delta
+}
+
+// antialiased 判断像素是否为抗锯齿产生的，
+// 参考 Vysniauskas 的 Anti-aliased Pixel and Intensity Slope Detector
+func antialiased(img, other *image.NRGBA, x1, y1 int) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	x0, y0 := maxInt(x1-1, 0), maxInt(y1-1, 0)
+	x2, y2 := minInt(x1+1, w-1), minInt(y1+1, h-1)
+
+	zeroes := 0
+	if x1 == x0 || x1 == x2 || y1 == y0 || y1 == y2 {
+		zeroes = 1
+	}
+	var (
+		min, max               float64
+		minX, minY, maxX, maxY int
+	)
+	for x := x0; x <= x2; x++ {
+		for y := y0; y <= y2; y++ {
+			if x == x1 && y == y1 {
+				continue
+			}
+			delta := colorDelta(img, img, x1, y1, x, y, true)
+			switch {
+			case delta == 0:
+				zeroes++
+				if zeroes > 2 {
+					return false
+				}
+			case delta < min:
+				min, minX, minY = delta, x, y
+			case delta > max:
+				max, maxX, maxY = delta, x, y
+			}
+		}
+	}
+	if min == 0 || max == 0 {
+		return false
+	}
+
+	return (manySiblings(img, minX, minY) && manySiblings(other, minX, minY)) ||
+		(manySiblings(img, maxX, maxY) && manySiblings(other, maxX, maxY))
+}
+
+// manySiblings 像素周围是否有 3 个以上相同颜色的像素
+func manySiblings(img *image.NRGBA, x1, y1 int) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if x1 >= w || y1 >= h {
+		return false
+	}
+	x0, y0 := maxInt(x1-1, 0), maxInt(y1-1, 0)
+	x2, y2 := minInt(x1+1, w-1), minInt(y1+1, h-1)
+
+	zeroes := 0
+	if x1 == x0 || x1 == x2 || y1 == y0 || y1 == y2 {
+		zeroes = 1
+	}
+	for x := x0; x <= x2; x++ {
+		for y := y0; y <= y2; y++ {
+			if x == x1 && y == y1 {
+				continue
+			}
+			if samePixel(img, img, x1, y1, x, y) {
+				zeroes++
+			}
+			if zeroes > 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setGray 在差异图中绘制淡化的灰度像素
+func setGray(dst *image.NRGBA, x, y int, src *image.NRGBA, alpha float64) {
+	r, g, b, a := pixel(src, x, y)
+	v := uint8(math.Round(blend(rgb2y(r, g, b), alpha*a)))
+	dst.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
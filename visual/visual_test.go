@@ -0,0 +1,123 @@
+package visual
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zfile"
+)
+
+func newImage(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+	a, b := newImage(10, 10, white), newImage(10, 10, white)
+	r := Compare(a, b)
+	tt.EqualTrue(r.Match)
+	tt.Equal(0, r.DiffPixels)
+
+	b.SetNRGBA(5, 5, color.NRGBA{R: 250, G: 250, B: 250, A: 255})
+	tt.EqualTrue(Compare(a, b).Match)
+
+	for x := 2; x < 8; x++ {
+		for y := 2; y < 4; y++ {
+			b.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+	}
+	r = Compare(a, b)
+	tt.EqualFalse(r.Match)
+	tt.Equal(12, r.DiffPixels)
+	tt.Equal(0.12, r.DiffRatio)
+	tt.Equal(color.NRGBA{R: 255, A: 255}, r.Diff.NRGBAAt(2, 2))
+
+	tt.EqualTrue(Compare(a, b, func(o *Options) {
+		o.MaxDiffRatio = 0.15
+	}).Match)
+
+	r = Compare(a, b, func(o *Options) {
+		o.Ignore = []image.Rectangle{image.Rect(0, 0, 10, 3)}
+	})
+	tt.Equal(6, r.DiffPixels)
+	tt.Equal(30, r.IgnoredPixels)
+
+	r = Compare(a, newImage(10, 12, white))
+	tt.EqualTrue(r.SizeMismatch)
+	tt.EqualFalse(r.Match)
+	tt.Equal(20, r.DiffPixels)
+	tt.Equal(12, r.Height)
+}
+
+func TestCompareAntialiased(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	edge := func(shade uint8) *image.NRGBA {
+		img := newImage(8, 8, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		for y := 0; y < 8; y++ {
+			for x := 4; x < 8; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{A: 255})
+			}
+		}
+		img.SetNRGBA(3, 4, color.NRGBA{R: shade, G: shade, B: shade, A: 255})
+		return img
+	}
+
+	a, b := edge(255), edge(128)
+	r := Compare(a, b)
+	tt.Equal(0, r.DiffPixels)
+	tt.Equal(1, r.AAPixels)
+	tt.EqualTrue(r.Match)
+
+	r = Compare(a, b, func(o *Options) {
+		o.IncludeAA = true
+	})
+	tt.Equal(1, r.DiffPixels)
+}
+
+func TestBaseline(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+	b := &Baseline{Dir: t.TempDir(), Strict: true}
+	_, err := b.Check("home", newImage(4, 4, white))
+	tt.EqualTrue(errors.Is(err, ErrMissing))
+
+	b.Strict = false
+	res, err := b.Check("home", newImage(4, 4, white))
+	tt.NoError(err)
+	tt.EqualTrue(res.Created)
+	tt.EqualTrue(zfile.FileExist(b.Path("home")))
+
+	res, err = b.Check("home", newImage(4, 4, white))
+	tt.NoError(err)
+	tt.EqualTrue(res.Match)
+
+	res, err = b.Check("home", newImage(4, 4, color.NRGBA{A: 255}))
+	tt.EqualTrue(errors.Is(err, ErrMismatch))
+	tt.Equal(16, res.DiffPixels)
+	tt.EqualTrue(zfile.FileExist(res.Actual))
+	tt.EqualTrue(zfile.FileExist(res.DiffFile))
+
+	b.Update = true
+	res, err = b.Check("home", newImage(4, 4, color.NRGBA{A: 255}))
+	tt.NoError(err)
+	tt.EqualTrue(res.Updated)
+	tt.EqualFalse(zfile.FileExist(res.Baseline[:len(res.Baseline)-4] + ".diff.png"))
+
+	b.Update = false
+	res, err = b.Check("home", newImage(4, 4, color.NRGBA{A: 255}))
+	tt.NoError(err)
+	tt.EqualTrue(res.Match)
+}